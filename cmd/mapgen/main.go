@@ -2,26 +2,23 @@ package main
 
 import (
 	"flag"
-	"github.com/nduyhai/mapgen/internal/generator"
-	"github.com/nduyhai/mapgen/internal/parser"
+	"github.com/nduyhai/mapgen/internal/driver"
 	"log"
+	"strings"
 )
 
 func main() {
 	input := flag.String("input", "./example/mapper", "Directory to search for mapper interfaces")
-	output := flag.String("output", "./example/mapper_gen", "Directory to output generated code")
+	output := flag.String("output", "", "Directory to output generated code, mirroring the input directories; defaults to the directory of each package")
+	tags := flag.String("tags", "", "Comma-separated list of build tags used to load packages")
 	flag.Parse()
 
-	mappers, err := parser.ParseDir(*input)
-	if err != nil {
-		log.Fatal(err)
-		return
+	d := driver.NewDriver(*output)
+	if *tags != "" {
+		d.SetBuildTags(strings.Split(*tags, ","))
 	}
 
-	for _, mapper := range mappers {
-		if err := generator.Generate(mapper, *output); err != nil {
-			log.Fatal(err)
-			return
-		}
+	if err := d.Run(*input); err != nil {
+		log.Fatal(err)
 	}
 }
@@ -0,0 +1,102 @@
+// Package convert provides the built-in conversions used by the code generated by mapgen.
+//
+// Conversions that cannot fail are plain functions. Conversions that can fail
+// return an error, which mapper methods that cannot return one turn into a panic
+// through Must.
+package convert
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Integer is the set of integer types.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Float is the set of floating-point types.
+type Float interface {
+	~float32 | ~float64
+}
+
+// Number is the set of integer and floating-point types.
+type Number interface {
+	Integer | Float
+}
+
+// Numeric converts s to the numeric type T.
+// It fails when the value of s cannot be represented by T, e.g. when it overflows T
+// or when a fractional value is converted to an integer type.
+// Converting to a floating-point type only fails on overflow, rounding is allowed.
+func Numeric[T, S Number](s S) (T, error) {
+	t := T(s)
+	if isFloat[T]() {
+		// x-x is NaN for infinite values and 0 for finite ones
+		if t-t != 0 && s-s == 0 {
+			return t, fmt.Errorf("value %v overflows %T", s, t)
+		}
+		return t, nil
+	}
+	if S(t) != s || (t < 0) != (s < 0) {
+		return t, fmt.Errorf("value %v cannot be represented as %T", s, t)
+	}
+	return t, nil
+}
+
+// isFloat reports whether T is a floating-point type.
+func isFloat[T Number]() bool {
+	one, two := T(1), T(2)
+	return one/two != 0
+}
+
+// Must returns v, or panics if err is not nil.
+// It wraps calls to converters returning an error, e.g. Must(strconv.Atoi(s)).
+func Must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// IsZero reports whether v is the zero value of its type.
+func IsZero[T any](v T) bool {
+	return reflect.ValueOf(&v).Elem().IsZero()
+}
+
+// TimeToUnix returns t as the number of seconds elapsed since January 1, 1970 UTC.
+func TimeToUnix(t time.Time) int64 {
+	return t.Unix()
+}
+
+// UnixToTime returns the UTC time corresponding to the given Unix time in seconds.
+func UnixToTime(sec int64) time.Time {
+	return time.Unix(sec, 0).UTC()
+}
+
+// TimeToUnixMilli returns t as the number of milliseconds elapsed since January 1, 1970 UTC.
+func TimeToUnixMilli(t time.Time) int64 {
+	return t.UnixMilli()
+}
+
+// UnixMilliToTime returns the UTC time corresponding to the given Unix time in milliseconds.
+func UnixMilliToTime(msec int64) time.Time {
+	return time.UnixMilli(msec).UTC()
+}
+
+// TimeToRFC3339 formats t according to RFC 3339.
+func TimeToRFC3339(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+// RFC3339ToTime parses a time formatted according to RFC 3339.
+func RFC3339ToTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339, s)
+}
+
+// String returns the string representation of s.
+func String(s fmt.Stringer) string {
+	return s.String()
+}
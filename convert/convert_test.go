@@ -0,0 +1,56 @@
+package convert
+
+import (
+	"math"
+	"testing"
+)
+
+// numericCase checks the conversion of s to T.
+func numericCase[T, S Number](t *testing.T, name string, s S, want T, wantErr bool) {
+	t.Helper()
+	t.Run(name, func(t *testing.T) {
+		got, err := Numeric[T](s)
+		if (err != nil) != wantErr {
+			t.Fatalf("Numeric[%T](%v) error = %v, want error %v", want, s, err, wantErr)
+		}
+		if err == nil && got != want {
+			t.Errorf("Numeric[%T](%v) = %v, want %v", want, s, got, want)
+		}
+	})
+}
+
+func TestNumeric(t *testing.T) {
+	// Integers
+	numericCase[int64](t, "uint64 max to int64", uint64(math.MaxUint64), 0, true)
+	numericCase[int64](t, "int64 max as uint64 to int64", uint64(math.MaxInt64), math.MaxInt64, false)
+	numericCase[uint64](t, "int64 max to uint64", int64(math.MaxInt64), math.MaxInt64, false)
+	numericCase[uint64](t, "negative to uint64", int64(-1), 0, true)
+	numericCase[int32](t, "int64 above int32", int64(math.MaxInt32)+1, 0, true)
+	numericCase[int32](t, "int64 below int32", int64(math.MinInt32)-1, 0, true)
+	numericCase[int32](t, "int64 min int32", int64(math.MinInt32), math.MinInt32, false)
+	numericCase[uint8](t, "int to uint8", 255, 255, false)
+	numericCase[uint8](t, "int above uint8", 256, 0, true)
+	numericCase[int8](t, "uint8 above int8", uint8(128), 0, true)
+
+	// Floats to integers
+	numericCase[int](t, "whole float to int", 42.0, 42, false)
+	numericCase[int](t, "fractional float to int", 1.5, 0, true)
+	numericCase[int](t, "negative fractional float to int", -0.5, 0, true)
+	numericCase[uint](t, "negative float to uint", -1.0, 0, true)
+	numericCase[int64](t, "float above int64", 1e19, 0, true)
+	numericCase[int32](t, "float above int32", float64(math.MaxInt32)+1, 0, true)
+	numericCase[int](t, "NaN to int", math.NaN(), 0, true)
+	numericCase[int](t, "infinity to int", math.Inf(1), 0, true)
+
+	// Integers to floats are rounded
+	numericCase[float64](t, "uint64 max to float64", uint64(math.MaxUint64), float64(math.MaxUint64), false)
+	numericCase[float32](t, "int64 to float32", int64(1<<24+1), float32(1<<24), false)
+
+	// Floats to floats
+	numericCase[float32](t, "float64 above float32", math.MaxFloat64, 0, true)
+	numericCase[float32](t, "float64 below float32", -math.MaxFloat64, 0, true)
+	numericCase[float32](t, "float64 max float32", float64(math.MaxFloat32), math.MaxFloat32, false)
+	numericCase[float32](t, "float64 rounded to float32", 0.1, float32(0.1), false)
+	numericCase[float32](t, "float64 infinity to float32", math.Inf(-1), float32(math.Inf(-1)), false)
+	numericCase[float64](t, "float32 to float64", float32(1.5), 1.5, false)
+}
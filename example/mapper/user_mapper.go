@@ -4,12 +4,14 @@ import "time"
 
 // +mapgen:mapper impl:userMapper
 type UserMapper interface {
-	// +mapgen:mapping from:UserName to:Name
+	// +mapgen:mapping from:Name to:UserName
 	// +mapgen:mapping from:CreatedAt to:CreatedAt using:TimeToUnix
 	// +mapgen:mapping ignore:PasswordHash
 	ToDTO(*User) *UserDTO
 
-	// +mapgen:mapping from:Name to:UserName
+	// +mapgen:mapping from:UserName to:Name
+	// +mapgen:mapping from:CreatedAt to:CreatedAt using:UnixToTime
+	// +mapgen:mapping ignore:PasswordHash
 	FromDTO(*UserDTO) *User
 }
 
@@ -20,5 +22,13 @@ type User struct {
 }
 type UserDTO struct {
 	UserName  string
-	CreatedAt time.Time
+	CreatedAt int64
+}
+
+func TimeToUnix(src time.Time) int64 {
+	return src.Unix()
+}
+
+func UnixToTime(src int64) time.Time {
+	return time.Unix(src, 0)
 }
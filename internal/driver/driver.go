@@ -0,0 +1,241 @@
+package driver
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"slices"
+
+	"github.com/nduyhai/mapgen/internal/generator"
+	"github.com/nduyhai/mapgen/internal/model"
+	"github.com/nduyhai/mapgen/internal/preprocessor"
+	"github.com/nduyhai/mapgen/internal/processor"
+	"github.com/nduyhai/mapgen/internal/resolver"
+	"github.com/nduyhai/mapgen/internal/scanner"
+)
+
+// Driver runs the whole code generation pipeline:
+// scan → directive extraction → processing → resolution → generation.
+type Driver struct {
+	scanner      *scanner.Scanner
+	preprocessor *preprocessor.Preprocessor
+	registry     *processor.Registry
+	resolver     *resolver.Resolver
+	outputDir    string
+}
+
+// NewDriver creates a new Driver that writes generated code under outputDir, or next to the sources
+// of each package when outputDir is empty.
+//
+// Usage:
+//
+//	driver := NewDriver("")
+//	err := driver.Run("./mapper")
+func NewDriver(outputDir string) *Driver {
+	s := scanner.NewScanner()
+	return &Driver{
+		scanner:      s,
+		preprocessor: preprocessor.NewPreprocessor(s.GetFileSet()),
+		registry:     processor.NewRegistry(),
+		resolver:     resolver.NewResolver(s.GetFileSet()),
+		outputDir:    outputDir,
+	}
+}
+
+// SetBuildTags sets the build tags used to select the files of the scanned packages.
+func (d *Driver) SetBuildTags(tags []string) {
+	d.scanner.SetBuildTags(tags)
+}
+
+// mapper is a mapper definition together with the interface it was declared on.
+type mapper struct {
+	definition model.MapperDefinition
+	typeSpec   *ast.TypeSpec
+}
+
+// methodDirective is the result of a directive that configures a mapper method,
+// together with the interface method it documents and the position of the directive.
+type methodDirective struct {
+	definition interface{}
+	field      *ast.Field
+	pos        token.Pos
+}
+
+// Run processes every package found in inputDir and its subdirectories, except for the testdata and
+// vendor directories and the directories whose name starts with '.' or '_', which the go command ignores too.
+//
+// The generated code belongs to the package of the mapper, as it refers to its types unqualified, so it is
+// written to the directory of the package. With an output directory, the files are written to the same
+// relative directory under it instead, e.g. to preview them.
+func (d *Driver) Run(inputDir string) error {
+	pkgs, err := d.scanner.LoadTree(inputDir)
+	if err != nil {
+		return err
+	}
+
+	absInputDir, err := filepath.Abs(inputDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve absolute path for %s: %w", inputDir, err)
+	}
+	for _, pkg := range pkgs {
+		outputDir := pkg.Dir
+		if d.outputDir != "" {
+			rel, err := filepath.Rel(absInputDir, pkg.Dir)
+			if err != nil {
+				return err
+			}
+			outputDir = filepath.Join(d.outputDir, rel)
+		}
+
+		if err := d.processPackage(pkg, outputDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processPackage runs every directive of the package through the processor
+// registry and generates the resulting definitions into outputDir.
+func (d *Driver) processPackage(pkg *scanner.Package, outputDir string) error {
+	var (
+		mappers    []*mapper
+		methods    []methodDirective
+		validators []model.ValidatorDefinition
+		// nodes are the declarations documented by the directives
+		nodes []ast.Node
+	)
+
+	for _, file := range pkg.Files {
+		directives, err := d.preprocessor.Process(file)
+		if err != nil {
+			return err
+		}
+		for _, directive := range directives {
+			result, err := d.registry.Process(directive)
+			if err != nil {
+				return fmt.Errorf("%s: %w", d.position(directive.Pos), err)
+			}
+			nodes = append(nodes, directive.Node)
+
+			switch def := result.(type) {
+			case model.MapperDefinition:
+				typeSpec, _ := directive.Node.(*ast.TypeSpec)
+				mappers = append(mappers, &mapper{definition: def, typeSpec: typeSpec})
+			case model.MappingDefinition, model.MethodDefinition:
+				field, _ := directive.Node.(*ast.Field)
+				methods = append(methods, methodDirective{definition: def, field: field, pos: directive.Pos})
+			case model.ValidatorDefinition:
+				validators = append(validators, def)
+			default:
+				return fmt.Errorf("%s: unsupported %s directive result %T", d.position(directive.Pos), directive.Type, result)
+			}
+		}
+	}
+
+	// The rest of the package may not type check, e.g. because of a function declared by the code to
+	// generate, but the declarations of the directives must
+	for _, typeErr := range pkg.TypeErrors {
+		for _, node := range nodes {
+			if node.Pos() <= typeErr.Pos && typeErr.Pos < node.End() {
+				return typeErr
+			}
+		}
+	}
+
+	for _, m := range methods {
+		method := findMethod(mappers, m.field)
+		if method == nil {
+			return fmt.Errorf("%s: directive is not attached to a mapper method", d.position(m.pos))
+		}
+
+		switch def := m.definition.(type) {
+		case model.MappingDefinition:
+			method.Mappings = append(method.Mappings, toFieldMappingRule(def, m.pos))
+		case model.MethodDefinition:
+			// The options of a method may be split across several directives, each set once
+			if def.UnmappedSource != "" {
+				if method.UnmappedSource != "" {
+					return fmt.Errorf("%s: unmappedSource of %s is already set by another directive", d.position(m.pos), method.Name)
+				}
+				method.UnmappedSource = def.UnmappedSource
+			}
+			if def.NullValueStrategy != "" {
+				if method.NullValueStrategy != "" {
+					return fmt.Errorf("%s: nullValueStrategy of %s is already set by another directive", d.position(m.pos), method.Name)
+				}
+				method.NullValueStrategy = def.NullValueStrategy
+			}
+		}
+	}
+
+	for _, m := range mappers {
+		if err := d.resolver.Resolve(pkg.Types, &m.definition); err != nil {
+			return err
+		}
+		if err := generator.Generate(&m.definition, outputDir); err != nil {
+			return err
+		}
+	}
+
+	for i := range validators {
+		if err := generator.GenerateValidator(&validators[i], outputDir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findMethod returns the mapper method declared by the interface method field, or nil if field is not
+// a method of a mapper interface, e.g. a struct field or an embedded interface.
+func findMethod(mappers []*mapper, field *ast.Field) *model.MapperMethod {
+	if field == nil || len(field.Names) == 0 {
+		return nil
+	}
+	for _, mp := range mappers {
+		if mp.typeSpec == nil {
+			continue
+		}
+		iface, ok := mp.typeSpec.Type.(*ast.InterfaceType)
+		if !ok || !slices.Contains(iface.Methods.List, field) {
+			continue
+		}
+
+		for i := range mp.definition.Methods {
+			if mp.definition.Methods[i].Name == field.Names[0].Name {
+				return &mp.definition.Methods[i]
+			}
+		}
+	}
+	return nil
+}
+
+// toFieldMappingRule converts a mapping definition into a rule of the mapper method.
+// A missing source or target field defaults to the other one, except for constants and expressions
+// which have no source field.
+func toFieldMappingRule(def model.MappingDefinition, pos token.Pos) model.FieldMappingRule {
+	rule := model.FieldMappingRule{
+		SourceField: def.From,
+		TargetField: def.To,
+		Ignore:      def.Ignore,
+		CustomFunc:  def.Using,
+		Convert:     def.Convert,
+		Constant:    def.Constant,
+		Default:     def.Default,
+		Expr:        def.Expr,
+		Pos:         pos,
+	}
+	if rule.TargetField == "" {
+		rule.TargetField = rule.SourceField
+	}
+	if rule.SourceField == "" && rule.Constant == "" && rule.Expr == "" {
+		rule.SourceField = rule.TargetField
+	}
+	return rule
+}
+
+// position formats pos using the scanner's file set.
+func (d *Driver) position(pos token.Pos) string {
+	return d.scanner.GetFileSet().Position(pos).String()
+}
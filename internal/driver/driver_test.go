@@ -0,0 +1,108 @@
+package driver
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// update rewrites the golden files of the test cases with the generated code.
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// TestRun generates the mappers of every package in testdata and compares them with the
+// golden *.gen.go files committed next to them, which are then built with the package.
+// A case whose directory holds an error.txt file must instead fail with an error containing its text.
+func TestRun(t *testing.T) {
+	entries, err := os.ReadDir("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join("testdata", entry.Name())
+		t.Run(entry.Name(), func(t *testing.T) {
+			out := t.TempDir()
+			err := NewDriver(out).Run(dir)
+
+			if want, readErr := os.ReadFile(filepath.Join(dir, "error.txt")); readErr == nil {
+				if err == nil || !strings.Contains(err.Error(), strings.TrimSpace(string(want))) {
+					t.Fatalf("Run() error = %v, want an error containing %q", err, strings.TrimSpace(string(want)))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			compareGolden(t, dir, out)
+			build(t, dir)
+		})
+	}
+}
+
+// compareGolden compares the files generated in out with the golden *.gen.go files of dir,
+// or replaces the golden files with them when -update is set.
+func compareGolden(t *testing.T, dir, out string) {
+	t.Helper()
+
+	generated, err := filepath.Glob(filepath.Join(out, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := filepath.Glob(filepath.Join(dir, "*.gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *update {
+		for _, name := range golden {
+			if err := os.Remove(name); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, name := range generated {
+			got, err := os.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, filepath.Base(name)), got, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return
+	}
+
+	if len(generated) != len(golden) {
+		t.Fatalf("generated %d files, want the %d golden files of %s", len(generated), len(golden), dir)
+	}
+	for _, name := range generated {
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := os.ReadFile(filepath.Join(dir, filepath.Base(name)))
+		if err != nil {
+			t.Fatalf("unexpected generated file %s: %v", filepath.Base(name), err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s differs from the golden file, run go test -update to accept it\ngot:\n%s\nwant:\n%s",
+				filepath.Base(name), got, want)
+		}
+	}
+}
+
+// build compiles the package of dir, together with its golden files.
+func build(t *testing.T, dir string) {
+	t.Helper()
+
+	// testdata directories are not matched by ./..., so the package is built by its path
+	cmd := exec.Command("go", "build", "./"+filepath.ToSlash(dir))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go build ./%s: %v\n%s", dir, err, output)
+	}
+}
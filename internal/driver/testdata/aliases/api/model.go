@@ -0,0 +1,14 @@
+// Package model is the API model, named like the domain model.
+package model
+
+// Version is the version of the API.
+const Version = "v1"
+
+// User is a user of the API.
+type User struct {
+	ID      int64
+	Name    string
+	Status  string
+	Label   string
+	Version string
+}
@@ -0,0 +1,12 @@
+// Package model is the domain model, named like the API model.
+package model
+
+// Status is the status of a user.
+type Status string
+
+// User is a user of the domain.
+type User struct {
+	ID     int64
+	Name   string
+	Status Status
+}
@@ -0,0 +1,29 @@
+// Package aliases maps between two packages named model, imported under aliases, and refers to
+// packages under aliases in constants and expressions.
+package aliases
+
+import (
+	str "strings"
+
+	dto "github.com/nduyhai/mapgen/internal/driver/testdata/aliases/api"
+	dom "github.com/nduyhai/mapgen/internal/driver/testdata/aliases/domain"
+)
+
+// UserMapper converts domain users to API users.
+//
+// +mapgen:mapper impl:userMapper
+type UserMapper interface {
+	// +mapgen:mapping to:Label expr:"str.ToUpper(in.Name)"
+	// +mapgen:mapping to:Version constant:dto.Version
+	ToDTO(in *dom.User) *dto.User
+	// Merge takes a parameter named like the packages, which are then imported under other names.
+	//
+	// +mapgen:mapping from:label to:Label using:normalize
+	// +mapgen:mapping to:Version constant:dto.Version
+	Merge(model *dom.User, label string) *dto.User
+}
+
+// normalize trims the spaces around a label.
+func normalize(label string) string {
+	return str.TrimSpace(label)
+}
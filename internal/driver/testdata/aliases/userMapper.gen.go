@@ -0,0 +1,44 @@
+// Code generated by mapgen. DO NOT EDIT.
+package aliases
+
+import (
+	model3 "github.com/nduyhai/mapgen/internal/driver/testdata/aliases/api"
+	model2 "github.com/nduyhai/mapgen/internal/driver/testdata/aliases/domain"
+	"strings"
+)
+
+type userMapper struct{}
+
+// NewUserMapper creates an implementation of UserMapper.
+func NewUserMapper() *userMapper {
+	return &userMapper{}
+}
+
+func (m *userMapper) ToDTO(in *model2.User) *model3.User {
+	if in == nil {
+		return nil
+	}
+	out := &model3.User{}
+	out.Label = strings.ToUpper(in.Name)
+	out.Version = model3.Version
+	out.ID = in.ID
+	out.Name = in.Name
+	out.Status = string(in.Status)
+	return out
+}
+
+func (m *userMapper) Merge(model *model2.User, label string) *model3.User {
+	out := &model3.User{}
+	out.Label = normalize(label)
+	out.Version = model3.Version
+	if model != nil {
+		out.ID = model.ID
+	}
+	if model != nil {
+		out.Name = model.Name
+	}
+	if model != nil {
+		out.Status = string(model.Status)
+	}
+	return out
+}
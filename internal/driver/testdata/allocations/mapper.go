@@ -0,0 +1,44 @@
+// Package allocations writes target fields through nested structs, allocating the pointed ones
+// only when a non-zero value is assigned.
+package allocations
+
+// User is the source of the mapping.
+type User struct {
+	City    string
+	Country string
+	Bio     string
+}
+
+// Address is nested in Profile.
+type Address struct {
+	City    string
+	Country string
+}
+
+// Profile is nested in UserDTO.
+type Profile struct {
+	Address *Address
+	Bio     string
+}
+
+// Meta is nested in UserDTO by value.
+type Meta struct {
+	Bio string
+}
+
+// UserDTO is the target of the mapping.
+type UserDTO struct {
+	Profile *Profile
+	Meta    Meta
+}
+
+// UserMapper nests the fields of users.
+//
+// +mapgen:mapper impl:userMapper
+type UserMapper interface {
+	// +mapgen:mapping from:City to:Profile.Address.City
+	// +mapgen:mapping from:Country to:Profile.Address.Country
+	// +mapgen:mapping from:Bio to:Profile.Bio
+	// +mapgen:mapping from:Bio to:Meta.Bio
+	ToDTO(in *User) *UserDTO
+}
@@ -0,0 +1,46 @@
+// Code generated by mapgen. DO NOT EDIT.
+package allocations
+
+import (
+	"github.com/nduyhai/mapgen/convert"
+)
+
+type userMapper struct{}
+
+// NewUserMapper creates an implementation of UserMapper.
+func NewUserMapper() *userMapper {
+	return &userMapper{}
+}
+
+func (m *userMapper) ToDTO(in *User) *UserDTO {
+	if in == nil {
+		return nil
+	}
+	out := &UserDTO{}
+	if v := in.City; !convert.IsZero(v) {
+		if out.Profile == nil {
+			out.Profile = &Profile{}
+		}
+		if out.Profile.Address == nil {
+			out.Profile.Address = &Address{}
+		}
+		out.Profile.Address.City = v
+	}
+	if v := in.Country; !convert.IsZero(v) {
+		if out.Profile == nil {
+			out.Profile = &Profile{}
+		}
+		if out.Profile.Address == nil {
+			out.Profile.Address = &Address{}
+		}
+		out.Profile.Address.Country = v
+	}
+	if v := in.Bio; !convert.IsZero(v) {
+		if out.Profile == nil {
+			out.Profile = &Profile{}
+		}
+		out.Profile.Bio = v
+	}
+	out.Meta.Bio = in.Bio
+	return out
+}
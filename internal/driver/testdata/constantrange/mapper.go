@@ -0,0 +1,18 @@
+// Package constantrange assigns a constant that does not fit the target field, which is rejected.
+package constantrange
+
+// UserDTO is the target of the mapping.
+type UserDTO struct {
+	Retries int8
+}
+
+// User is the source of the mapping.
+type User struct{}
+
+// UserMapper assigns 300 to an int8.
+//
+// +mapgen:mapper impl:userMapper
+type UserMapper interface {
+	// +mapgen:mapping to:Retries constant:300
+	ToDTO(in *User) *UserDTO
+}
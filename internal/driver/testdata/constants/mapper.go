@@ -0,0 +1,37 @@
+// Package constants assigns constant values and defaults to target fields.
+package constants
+
+// Status is the status of a user.
+type Status string
+
+// StatusActive is the status of the users that can sign in.
+const StatusActive Status = "active"
+
+// Profile is nested in User.
+type Profile struct {
+	Nickname string
+}
+
+// User is the source of the mapping.
+type User struct {
+	Profile *Profile
+}
+
+// UserDTO is the target of the mapping.
+type UserDTO struct {
+	Version  string
+	Status   Status
+	Retries  int8
+	Nickname string
+}
+
+// UserMapper assigns constants, and a default to Nickname when the profile is nil or has no nickname.
+//
+// +mapgen:mapper impl:userMapper
+type UserMapper interface {
+	// +mapgen:mapping to:Version constant:"v1"
+	// +mapgen:mapping to:Status constant:StatusActive
+	// +mapgen:mapping to:Retries constant:3
+	// +mapgen:mapping from:Profile.Nickname to:Nickname default:"anonymous"
+	ToDTO(in *User) *UserDTO
+}
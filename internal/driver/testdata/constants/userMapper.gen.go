@@ -0,0 +1,29 @@
+// Code generated by mapgen. DO NOT EDIT.
+package constants
+
+import (
+	"github.com/nduyhai/mapgen/convert"
+)
+
+type userMapper struct{}
+
+// NewUserMapper creates an implementation of UserMapper.
+func NewUserMapper() *userMapper {
+	return &userMapper{}
+}
+
+func (m *userMapper) ToDTO(in *User) *UserDTO {
+	if in == nil {
+		return nil
+	}
+	out := &UserDTO{}
+	out.Version = "v1"
+	out.Status = StatusActive
+	out.Retries = 3
+	if in.Profile != nil && !convert.IsZero(in.Profile.Nickname) {
+		out.Nickname = in.Profile.Nickname
+	} else {
+		out.Nickname = "anonymous"
+	}
+	return out
+}
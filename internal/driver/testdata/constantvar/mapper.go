@@ -0,0 +1,21 @@
+// Package constantvar assigns a variable as a constant, which is rejected.
+package constantvar
+
+// DefaultVersion is a variable rather than a constant.
+var DefaultVersion = "v1"
+
+// UserDTO is the target of the mapping.
+type UserDTO struct {
+	Version string
+}
+
+// User is the source of the mapping.
+type User struct{}
+
+// UserMapper assigns DefaultVersion to Version.
+//
+// +mapgen:mapper impl:userMapper
+type UserMapper interface {
+	// +mapgen:mapping to:Version constant:DefaultVersion
+	ToDTO(in *User) *UserDTO
+}
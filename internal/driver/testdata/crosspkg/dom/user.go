@@ -0,0 +1,8 @@
+// Package dom declares the domain types mapped by the crosspkg mapper.
+package dom
+
+// User is a domain user.
+type User struct {
+	ID   int64
+	Name string
+}
@@ -0,0 +1,25 @@
+// Package crosspkg maps types of another package imported under an alias,
+// including slices and maps of them.
+package crosspkg
+
+import (
+	"context"
+
+	d "github.com/nduyhai/mapgen/internal/driver/testdata/crosspkg/dom"
+)
+
+// UserDTO is the transfer object of a user.
+type UserDTO struct {
+	ID   int64
+	Name string
+}
+
+// UserMapper maps users of the dom package.
+//
+// +mapgen:mapper
+type UserMapper interface {
+	ToDTO(user *d.User) *UserDTO
+	FromDTO(dto *UserDTO) *d.User
+	List(ctx context.Context, users []*d.User) ([]*UserDTO, error)
+	Index(users map[string]*d.User) map[string]*UserDTO
+}
@@ -0,0 +1,56 @@
+// Code generated by mapgen. DO NOT EDIT.
+package crosspkg
+
+import (
+	"context"
+	"github.com/nduyhai/mapgen/internal/driver/testdata/crosspkg/dom"
+)
+
+type usermapper_mapper struct{}
+
+// NewUsermapper_mapper creates an implementation of UserMapper.
+func NewUsermapper_mapper() *usermapper_mapper {
+	return &usermapper_mapper{}
+}
+
+func (m *usermapper_mapper) ToDTO(in *dom.User) *UserDTO {
+	if in == nil {
+		return nil
+	}
+	out := &UserDTO{}
+	out.ID = in.ID
+	out.Name = in.Name
+	return out
+}
+
+func (m *usermapper_mapper) FromDTO(in *UserDTO) *dom.User {
+	if in == nil {
+		return nil
+	}
+	out := &dom.User{}
+	out.ID = in.ID
+	out.Name = in.Name
+	return out
+}
+
+func (m *usermapper_mapper) List(ctx context.Context, in []*dom.User) ([]*UserDTO, error) {
+	if in == nil {
+		return nil, nil
+	}
+	out := make([]*UserDTO, len(in))
+	for i, v := range in {
+		out[i] = m.ToDTO(v)
+	}
+	return out, nil
+}
+
+func (m *usermapper_mapper) Index(in map[string]*dom.User) map[string]*UserDTO {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]*UserDTO, len(in))
+	for k, v := range in {
+		out[k] = m.ToDTO(v)
+	}
+	return out
+}
@@ -0,0 +1,22 @@
+// Package duplicatetarget maps the same target field twice, which is rejected.
+package duplicatetarget
+
+// User is the source of the mapping.
+type User struct {
+	FirstName string
+	LastName  string
+}
+
+// UserDTO is the target of the mapping.
+type UserDTO struct {
+	Name string
+}
+
+// UserMapper maps Name from two source fields.
+//
+// +mapgen:mapper impl:userMapper
+type UserMapper interface {
+	// +mapgen:mapping from:FirstName to:Name
+	// +mapgen:mapping from:LastName to:Name
+	ToDTO(in *User) *UserDTO
+}
@@ -0,0 +1,54 @@
+// Package expr assigns Go expressions over the sources of the methods, referring to the packages
+// imported by the mapper's file under their names in the file.
+package expr
+
+import (
+	stdctx "context"
+	"strings"
+	tm "time"
+)
+
+// User is a source of the mappings.
+type User struct {
+	FirstName string
+	LastName  string
+	Tags      []string
+	Born      tm.Time
+}
+
+// Flat is a source of the mappings that cannot be nil.
+type Flat struct {
+	Code string
+}
+
+// UserDTO is the target of the mappings.
+type UserDTO struct {
+	FullName string
+	Tags     string
+	Age      int
+	Name     string
+	Code     string
+}
+
+// Lookup resolves the display name of a user.
+func Lookup(ctx stdctx.Context, name string) string {
+	return strings.TrimSpace(name)
+}
+
+// UserMapper reads every source field through expressions.
+//
+// +mapgen:mapper impl:userMapper unmappedSource:error unmappedTarget:ignore
+type UserMapper interface {
+	// +mapgen:mapping to:FullName expr:`in.FirstName + " " + in.LastName`
+	// +mapgen:mapping to:Tags expr:"strings.Join(in.Tags, \",\")"
+	// +mapgen:mapping to:Age expr:"tm.Now().Year() - in.Born.Year()"
+	ToDTO(in *User) *UserDTO
+	// Multi skips the expressions reading u when it is nil.
+	//
+	// +mapgen:mapping to:FullName expr:`u.FirstName + " " + u.LastName`
+	// +mapgen:mapping to:Tags expr:"strings.Join(u.Tags, \",\")"
+	// +mapgen:mapping to:Age expr:"tm.Now().Year() - u.Born.Year()"
+	// +mapgen:mapping to:Name expr:"Lookup(ctx, u.FirstName)"
+	// +mapgen:mapping to:Code expr:"f.Code"
+	Multi(ctx stdctx.Context, u *User, f Flat) *UserDTO
+}
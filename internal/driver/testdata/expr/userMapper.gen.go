@@ -0,0 +1,44 @@
+// Code generated by mapgen. DO NOT EDIT.
+package expr
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+type userMapper struct{}
+
+// NewUserMapper creates an implementation of UserMapper.
+func NewUserMapper() *userMapper {
+	return &userMapper{}
+}
+
+func (m *userMapper) ToDTO(in *User) *UserDTO {
+	if in == nil {
+		return nil
+	}
+	out := &UserDTO{}
+	out.FullName = in.FirstName + " " + in.LastName
+	out.Tags = strings.Join(in.Tags, ",")
+	out.Age = time.Now().Year() - in.Born.Year()
+	return out
+}
+
+func (m *userMapper) Multi(ctx context.Context, u *User, f Flat) *UserDTO {
+	out := &UserDTO{}
+	if u != nil {
+		out.FullName = u.FirstName + " " + u.LastName
+	}
+	if u != nil {
+		out.Tags = strings.Join(u.Tags, ",")
+	}
+	if u != nil {
+		out.Age = time.Now().Year() - u.Born.Year()
+	}
+	if u != nil {
+		out.Name = Lookup(ctx, u.FirstName)
+	}
+	out.Code = f.Code
+	return out
+}
@@ -0,0 +1,20 @@
+// Package exprinvalid assigns an expression of the wrong type, which is rejected.
+package exprinvalid
+
+// User is the source of the mapping.
+type User struct {
+	Age int
+}
+
+// UserDTO is the target of the mapping.
+type UserDTO struct {
+	Adult string
+}
+
+// UserMapper assigns a bool to a string.
+//
+// +mapgen:mapper impl:userMapper
+type UserMapper interface {
+	// +mapgen:mapping to:Adult expr:"in.Age >= 18"
+	ToDTO(in *User) *UserDTO
+}
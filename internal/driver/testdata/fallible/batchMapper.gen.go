@@ -0,0 +1,56 @@
+// Code generated by mapgen. DO NOT EDIT.
+package fallible
+
+import (
+	"fmt"
+	"github.com/nduyhai/mapgen/convert"
+)
+
+type batchMapper struct{}
+
+// NewBatchMapper creates an implementation of BatchMapper.
+func NewBatchMapper() *batchMapper {
+	return &batchMapper{}
+}
+
+func (m *batchMapper) EventToDTO(in *Event) (*EventDTO, error) {
+	if in == nil {
+		return nil, nil
+	}
+	out := &EventDTO{}
+	at, err := convert.RFC3339ToTime(in.At)
+	if err != nil {
+		return nil, fmt.Errorf("mapping Event.At: %w", err)
+	}
+	out.At = at
+	count, err := convert.Numeric[int32](in.Count)
+	if err != nil {
+		return nil, fmt.Errorf("mapping Event.Count: %w", err)
+	}
+	out.Count = count
+	return out, nil
+}
+
+func (m *batchMapper) ToDTO(in *Batch) *BatchDTO {
+	if in == nil {
+		return nil
+	}
+	out := &BatchDTO{}
+	out.Events = convert.Must(m.mapSlice1(in.Events))
+	return out
+}
+
+func (m *batchMapper) mapSlice1(in []*Event) ([]*EventDTO, error) {
+	if in == nil {
+		return nil, nil
+	}
+	out := make([]*EventDTO, len(in))
+	for i, v := range in {
+		elem, err := m.EventToDTO(v)
+		if err != nil {
+			return nil, fmt.Errorf("index %d: %w", i, err)
+		}
+		out[i] = elem
+	}
+	return out, nil
+}
@@ -0,0 +1,48 @@
+// Package fallible maps fields whose conversion can fail.
+package fallible
+
+import "time"
+
+// Event is the source of the mapping.
+type Event struct {
+	At    string
+	Count int64
+}
+
+// EventDTO is the target of the mapping.
+type EventDTO struct {
+	At    time.Time
+	Count int32
+}
+
+// Batch is the source of the mapping of nested events.
+type Batch struct {
+	Events []*Event
+}
+
+// BatchDTO is the target of the mapping of nested events.
+type BatchDTO struct {
+	Events []*EventDTO
+}
+
+// EventMapper converts the fields of Event, which can fail.
+//
+// +mapgen:mapper impl:eventMapper
+type EventMapper interface {
+	// ToDTO returns the conversion errors of the fields matched by name.
+	ToDTO(in *Event) (*EventDTO, error)
+	// MustToDTO panics on conversion errors, its conversions being selected.
+	//
+	// +mapgen:mapping to:At convert:rfc3339
+	// +mapgen:mapping to:Count convert:numeric
+	MustToDTO(in *Event) *EventDTO
+}
+
+// BatchMapper converts the events of a batch with a method returning an error.
+//
+// +mapgen:mapper impl:batchMapper
+type BatchMapper interface {
+	EventToDTO(in *Event) (*EventDTO, error)
+	// ToDTO panics on the errors of EventToDTO, which maps the nested events.
+	ToDTO(in *Batch) *BatchDTO
+}
@@ -0,0 +1,10 @@
+// Package falliblecollection maps a collection whose elements can fail to convert in a method
+// without an error, which is rejected.
+package falliblecollection
+
+// CountMapper narrows counts without selecting the conversion.
+//
+// +mapgen:mapper impl:countMapper
+type CountMapper interface {
+	Narrow(in []int64) []int32
+}
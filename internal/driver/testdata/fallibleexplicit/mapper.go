@@ -0,0 +1,21 @@
+// Package fallibleexplicit maps a field whose conversion can fail in a method without an error,
+// with a mapping that does not select the conversion, which is rejected.
+package fallibleexplicit
+
+// Event is the source of the mapping.
+type Event struct {
+	Count int64
+}
+
+// EventDTO is the target of the mapping.
+type EventDTO struct {
+	N int32
+}
+
+// EventMapper converts Count to N without selecting the conversion.
+//
+// +mapgen:mapper impl:eventMapper
+type EventMapper interface {
+	// +mapgen:mapping from:Count to:N
+	ToDTO(in *Event) *EventDTO
+}
@@ -0,0 +1,20 @@
+// Package fallibleimplicit matches a field whose conversion can fail in a method without an error,
+// which is rejected.
+package fallibleimplicit
+
+// Event is the source of the mapping.
+type Event struct {
+	Count int64
+}
+
+// EventDTO is the target of the mapping.
+type EventDTO struct {
+	Count int32
+}
+
+// EventMapper converts Count without selecting the conversion.
+//
+// +mapgen:mapper impl:eventMapper
+type EventMapper interface {
+	ToDTO(in *Event) *EventDTO
+}
@@ -0,0 +1,49 @@
+// Package matching pairs fields by tag names, naming strategy and prefixes.
+package matching
+
+// UserRow is a database row.
+type UserRow struct {
+	UserName string `db:"user_name"`
+	Email    string `db:"email_address"`
+	Internal string `db:"-"`
+}
+
+// UserDTO is an API object.
+type UserDTO struct {
+	Name     string `json:"user_name"`
+	Mail     string `json:"email_address,omitempty"`
+	Internal string `json:"internal"`
+}
+
+// PbUser is a protoc generated message.
+type PbUser struct {
+	PbUserId   int64  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	PbFullName string `protobuf:"bytes,2,opt,name=full_name,json=fullName,proto3" json:"full_name,omitempty"`
+}
+
+// User is a domain user.
+type User struct {
+	UserID   int64
+	FullName string
+}
+
+// RowMapper pairs database rows and API objects by tag names.
+//
+// +mapgen:mapper impl:rowMapper matchBy:db,json
+type RowMapper interface {
+	ToDTO(in *UserRow) *UserDTO
+}
+
+// ProtoMapper pairs protobuf messages by their protobuf names.
+//
+// +mapgen:mapper impl:protoMapper matchBy:tag=protobuf naming:normalized
+type ProtoMapper interface {
+	FromProto(in *PbUser) *User
+}
+
+// PrefixMapper pairs protobuf messages by their Go names once stripped of their prefix.
+//
+// +mapgen:mapper impl:prefixMapper naming:caseInsensitive stripSourcePrefix:Pb
+type PrefixMapper interface {
+	FromProto(in *PbUser) *User
+}
@@ -0,0 +1,21 @@
+// Package matchingambiguous pairs a target field with two source fields of the same normalized name,
+// only one of which can be converted to it, which is rejected.
+package matchingambiguous
+
+// UserRow is the source of the mapping.
+type UserRow struct {
+	UserID  int64
+	User_ID []int
+}
+
+// User is the target of the mapping.
+type User struct {
+	UserId int64
+}
+
+// UserMapper cannot choose the source of UserId.
+//
+// +mapgen:mapper impl:userMapper naming:normalized
+type UserMapper interface {
+	FromRow(in *UserRow) *User
+}
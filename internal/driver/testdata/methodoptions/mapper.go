@@ -0,0 +1,24 @@
+// Package methodoptions configures a method with several +mapgen:method directives.
+package methodoptions
+
+// User is the source of the mapping.
+type User struct {
+	Name     string
+	Password string
+}
+
+// UserDTO is the target of the mapping.
+type UserDTO struct {
+	Name string
+}
+
+// UserMapper reports unmapped source fields, except for Update.
+//
+// +mapgen:mapper impl:userMapper unmappedSource:error
+type UserMapper interface {
+	// Update leaves Password unmapped and keeps the name when the source one is empty.
+	//
+	// +mapgen:method unmappedSource:ignore
+	// +mapgen:method nullValueStrategy:skip
+	Update(in *User, out *UserDTO)
+}
@@ -0,0 +1,22 @@
+// Code generated by mapgen. DO NOT EDIT.
+package methodoptions
+
+import (
+	"github.com/nduyhai/mapgen/convert"
+)
+
+type userMapper struct{}
+
+// NewUserMapper creates an implementation of UserMapper.
+func NewUserMapper() *userMapper {
+	return &userMapper{}
+}
+
+func (m *userMapper) Update(in *User, out *UserDTO) {
+	if in == nil {
+		return
+	}
+	if !convert.IsZero(in.Name) {
+		out.Name = in.Name
+	}
+}
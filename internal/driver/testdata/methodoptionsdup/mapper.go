@@ -0,0 +1,21 @@
+// Package methodoptionsdup sets the same method option twice, which is rejected.
+package methodoptionsdup
+
+// User is the source of the mapping.
+type User struct {
+	Name string
+}
+
+// UserDTO is the target of the mapping.
+type UserDTO struct {
+	Name string
+}
+
+// UserMapper configures Update twice.
+//
+// +mapgen:mapper impl:userMapper
+type UserMapper interface {
+	// +mapgen:method nullValueStrategy:skip
+	// +mapgen:method nullValueStrategy:overwrite
+	Update(in *User, out *UserDTO)
+}
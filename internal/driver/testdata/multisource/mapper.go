@@ -0,0 +1,39 @@
+// Package multisource maps several sources to a single target.
+package multisource
+
+import gocontext "context"
+
+// User is a source of the mapping.
+type User struct {
+	Name string
+	Age  int
+}
+
+// Address is a source of the mapping.
+type Address struct {
+	City   string
+	Street string
+}
+
+// Extra is a source of the mapping that cannot be nil.
+type Extra struct {
+	Note string
+}
+
+// UserDTO is the target of the mapping.
+type UserDTO struct {
+	Name   string
+	Age    int
+	Town   string
+	Street string
+	Note   string
+}
+
+// UserMapper merges users with their address, each source being checked for nil before its fields are read.
+//
+// +mapgen:mapper impl:userMapper
+type UserMapper interface {
+	// +mapgen:mapping from:a.City to:Town
+	// +mapgen:mapping from:Street
+	ToDTO(ctx gocontext.Context, u *User, a *Address, e Extra) *UserDTO
+}
@@ -0,0 +1,25 @@
+// Package multisourceambiguous reads an unqualified field found in several sources, which is rejected.
+package multisourceambiguous
+
+// User is a source of the mapping.
+type User struct {
+	Name string
+}
+
+// Company is a source of the mapping.
+type Company struct {
+	Name string
+}
+
+// UserDTO is the target of the mapping.
+type UserDTO struct {
+	Title string
+}
+
+// UserMapper cannot choose the source of Title.
+//
+// +mapgen:mapper impl:userMapper
+type UserMapper interface {
+	// +mapgen:mapping from:Name to:Title
+	ToDTO(u *User, c *Company) *UserDTO
+}
@@ -0,0 +1,7 @@
+// Package notinterface declares a mapper on a struct type, which is rejected.
+package notinterface
+
+// UserMapper is not an interface.
+//
+// +mapgen:mapper
+type UserMapper struct{}
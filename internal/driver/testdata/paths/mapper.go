@@ -0,0 +1,33 @@
+// Package paths reads source fields through nested pointer fields.
+package paths
+
+// Address is nested in Profile.
+type Address struct {
+	City string
+}
+
+// Profile is nested in User.
+type Profile struct {
+	Address *Address
+	Bio     string
+}
+
+// User is the source of the mapping.
+type User struct {
+	Profile *Profile
+}
+
+// UserDTO is the target of the mapping.
+type UserDTO struct {
+	City string
+	Bio  string
+}
+
+// UserMapper flattens the profile of users, leaving a field unset when a pointer on its path is nil.
+//
+// +mapgen:mapper impl:userMapper
+type UserMapper interface {
+	// +mapgen:mapping from:Profile.Address.City to:City
+	// +mapgen:mapping from:Profile.Bio to:Bio
+	ToDTO(in *User) *UserDTO
+}
@@ -0,0 +1,23 @@
+// Code generated by mapgen. DO NOT EDIT.
+package paths
+
+type userMapper struct{}
+
+// NewUserMapper creates an implementation of UserMapper.
+func NewUserMapper() *userMapper {
+	return &userMapper{}
+}
+
+func (m *userMapper) ToDTO(in *User) *UserDTO {
+	if in == nil {
+		return nil
+	}
+	out := &UserDTO{}
+	if in.Profile != nil && in.Profile.Address != nil {
+		out.City = in.Profile.Address.City
+	}
+	if in.Profile != nil {
+		out.Bio = in.Profile.Bio
+	}
+	return out
+}
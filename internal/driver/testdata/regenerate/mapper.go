@@ -0,0 +1,22 @@
+// Package regenerate refers to the generated implementation of its mapper, which is regenerated
+// although the package does not type check without it.
+package regenerate
+
+// Event is the source of the mapping.
+type Event struct {
+	Name string
+}
+
+// EventDTO is the target of the mapping.
+type EventDTO struct {
+	Name string
+}
+
+// EventMapper converts events.
+//
+// +mapgen:mapper impl:eventMapper
+type EventMapper interface {
+	ToDTO(in Event) EventDTO
+}
+
+var _ EventMapper = NewEventMapper()
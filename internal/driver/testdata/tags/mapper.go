@@ -0,0 +1,42 @@
+// Package tags configures the mapping of fields with mapgen struct tags on both sides.
+package tags
+
+import "time"
+
+// Address is nested in User.
+type Address struct {
+	City string
+}
+
+// User is the source of the mapping.
+type User struct {
+	UserName string
+	Created  time.Time
+	Score    int64
+	Address  Address
+	Nickname string `mapgen:"DisplayName"`
+	Password string `mapgen:"-"`
+	Internal string
+}
+
+// UserDTO is the target of the mapping.
+type UserDTO struct {
+	Name        string `mapgen:"UserName"`
+	CreatedAt   int64  `mapgen:"Created,using=TimeToUnix"`
+	Score       int32  `mapgen:",convert=numeric"`
+	City        string `mapgen:"Address.City"`
+	DisplayName string
+	Internal    string `mapgen:"-"`
+}
+
+// TimeToUnix converts a time to Unix seconds.
+func TimeToUnix(t time.Time) int64 {
+	return t.Unix()
+}
+
+// UserMapper maps users configured by tags.
+//
+// +mapgen:mapper impl:userMapper
+type UserMapper interface {
+	ToDTO(in *User) *UserDTO
+}
@@ -0,0 +1,26 @@
+// Code generated by mapgen. DO NOT EDIT.
+package tags
+
+import (
+	"github.com/nduyhai/mapgen/convert"
+)
+
+type userMapper struct{}
+
+// NewUserMapper creates an implementation of UserMapper.
+func NewUserMapper() *userMapper {
+	return &userMapper{}
+}
+
+func (m *userMapper) ToDTO(in *User) *UserDTO {
+	if in == nil {
+		return nil
+	}
+	out := &UserDTO{}
+	out.Name = in.UserName
+	out.CreatedAt = TimeToUnix(in.Created)
+	out.Score = convert.Must(convert.Numeric[int32](in.Score))
+	out.City = in.Address.City
+	out.DisplayName = in.Nickname
+	return out
+}
@@ -0,0 +1,20 @@
+// Package tagsduplicate tags two source fields with the same target field, which is rejected.
+package tagsduplicate
+
+// User is the source of the mapping.
+type User struct {
+	First string `mapgen:"Name"`
+	Last  string `mapgen:"Name"`
+}
+
+// UserDTO is the target of the mapping.
+type UserDTO struct {
+	Name string
+}
+
+// UserMapper cannot choose the source of Name.
+//
+// +mapgen:mapper impl:userMapper
+type UserMapper interface {
+	ToDTO(in *User) *UserDTO
+}
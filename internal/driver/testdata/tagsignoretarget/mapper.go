@@ -0,0 +1,22 @@
+// Package tagsignoretarget ignores a target field by tag, the source field of the same name being
+// still reported as unmapped.
+package tagsignoretarget
+
+// User is the source of the mapping.
+type User struct {
+	Name     string
+	Internal string
+}
+
+// UserDTO is the target of the mapping.
+type UserDTO struct {
+	Name     string
+	Internal string `mapgen:"-"`
+}
+
+// UserMapper reports the source fields that are never read.
+//
+// +mapgen:mapper impl:userMapper unmappedSource:error
+type UserMapper interface {
+	ToDTO(in *User) *UserDTO
+}
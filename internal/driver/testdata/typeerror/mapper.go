@@ -0,0 +1,14 @@
+// Package typeerror declares a mapper on a type that does not exist, which is rejected.
+package typeerror
+
+// EventDTO is the target of the mapping.
+type EventDTO struct {
+	Name string
+}
+
+// EventMapper converts events.
+//
+// +mapgen:mapper impl:eventMapper
+type EventMapper interface {
+	ToDTO(in Event) EventDTO
+}
@@ -0,0 +1,32 @@
+// Package underlying converts named types to and from their underlying types.
+package underlying
+
+import "time"
+
+// UserID identifies a user.
+type UserID int64
+
+// OrderID identifies an order.
+type OrderID int64
+
+// Order is the source of the mapping.
+type Order struct {
+	ID      UserID
+	Ref     UserID
+	Timeout time.Duration
+}
+
+// OrderDTO is the target of the mapping.
+type OrderDTO struct {
+	ID      int64
+	Ref     OrderID
+	Timeout int64
+}
+
+// OrderMapper converts Ref between unrelated named types once selected.
+//
+// +mapgen:mapper impl:orderMapper
+type OrderMapper interface {
+	// +mapgen:mapping to:Ref convert:underlying
+	ToDTO(in *Order) *OrderDTO
+}
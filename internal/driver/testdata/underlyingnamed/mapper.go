@@ -0,0 +1,26 @@
+// Package underlyingnamed maps unrelated named types without selecting a conversion, which is rejected.
+package underlyingnamed
+
+// UserID identifies a user.
+type UserID int64
+
+// OrderID identifies an order.
+type OrderID int64
+
+// Order is the source of the mapping.
+type Order struct {
+	Ref UserID
+}
+
+// OrderDTO is the target of the mapping.
+type OrderDTO struct {
+	Ref OrderID
+}
+
+// OrderMapper maps Ref with the default conversions.
+//
+// +mapgen:mapper impl:orderMapper
+type OrderMapper interface {
+	// +mapgen:mapping to:Ref
+	ToDTO(in *Order) *OrderDTO
+}
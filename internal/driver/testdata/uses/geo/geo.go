@@ -0,0 +1,17 @@
+// Package geo declares a mapper named like the one of the uses package.
+package geo
+
+// Address is a geographic address.
+type Address struct {
+	City string
+}
+
+// AddressDTO is the API object of an Address.
+type AddressDTO struct {
+	City string
+}
+
+// AddressMapper maps geographic addresses, implemented by hand.
+type AddressMapper interface {
+	ToDTO(in *Address) *AddressDTO
+}
@@ -0,0 +1,40 @@
+// Package uses injects the mappers it uses into the generated implementation through its constructor.
+package uses
+
+import "github.com/nduyhai/mapgen/internal/driver/testdata/uses/geo"
+
+// Address is a postal address.
+type Address struct {
+	Street string
+}
+
+// AddressDTO is the API object of an Address.
+type AddressDTO struct {
+	Street string
+}
+
+// User is the source of the mapping.
+type User struct {
+	Home     *Address
+	Location *geo.Address
+}
+
+// UserDTO is the target of the mapping.
+type UserDTO struct {
+	Home     *AddressDTO
+	Location *geo.AddressDTO
+}
+
+// AddressMapper maps postal addresses.
+//
+// +mapgen:mapper impl:addressMapper
+type AddressMapper interface {
+	ToDTO(in *Address) *AddressDTO
+}
+
+// UserMapper maps the addresses of users with the mappers it uses, named alike.
+//
+// +mapgen:mapper impl:userMapper uses:AddressMapper,geo.AddressMapper
+type UserMapper interface {
+	ToDTO(in *User) *UserDTO
+}
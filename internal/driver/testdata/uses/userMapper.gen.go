@@ -0,0 +1,29 @@
+// Code generated by mapgen. DO NOT EDIT.
+package uses
+
+import (
+	"github.com/nduyhai/mapgen/internal/driver/testdata/uses/geo"
+)
+
+type userMapper struct {
+	addressMapper  AddressMapper
+	addressMapper2 geo.AddressMapper
+}
+
+// NewUserMapper creates an implementation of UserMapper using the given mappers.
+func NewUserMapper(addressMapper AddressMapper, addressMapper2 geo.AddressMapper) *userMapper {
+	return &userMapper{
+		addressMapper:  addressMapper,
+		addressMapper2: addressMapper2,
+	}
+}
+
+func (m *userMapper) ToDTO(in *User) *UserDTO {
+	if in == nil {
+		return nil
+	}
+	out := &UserDTO{}
+	out.Home = m.addressMapper.ToDTO(in.Home)
+	out.Location = m.addressMapper2.ToDTO(in.Location)
+	return out
+}
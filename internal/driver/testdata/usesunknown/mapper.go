@@ -0,0 +1,12 @@
+// Package usesunknown uses a mapper that does not exist, which is rejected.
+package usesunknown
+
+// User is the source of the mapping.
+type User struct{}
+
+// UserMapper uses AddressMapper, which is not declared.
+//
+// +mapgen:mapper impl:userMapper uses:AddressMapper
+type UserMapper interface {
+	ToDTO(in *User) *User
+}
@@ -0,0 +1,45 @@
+// Package using converts fields with using: functions of its own and of a package imported under an alias.
+package using
+
+import (
+	"strconv"
+	"time"
+
+	tu "github.com/nduyhai/mapgen/internal/driver/testdata/using/timeutil"
+)
+
+// User is the source of the mapping.
+type User struct {
+	CreatedAt time.Time
+	Age       string
+}
+
+// UserDTO is the target of the mapping.
+type UserDTO struct {
+	CreatedAt int64
+	Age       int
+}
+
+// CreatedUnix returns the creation time of the user in Unix seconds.
+func (u User) CreatedUnix() int64 {
+	return tu.ToUnix(u.CreatedAt)
+}
+
+// ParseAge parses an age, which can fail.
+func ParseAge(age string) (int, error) {
+	return strconv.Atoi(age)
+}
+
+// UserMapper converts the fields with using: functions.
+//
+// +mapgen:mapper impl:userMapper
+type UserMapper interface {
+	// +mapgen:mapping to:CreatedAt using:tu.ToUnix
+	// +mapgen:mapping to:Age using:ParseAge
+	ToDTO(in *User) (*UserDTO, error)
+	// MustToDTO panics when ParseAge fails.
+	//
+	// +mapgen:mapping to:CreatedAt using:tu.ToUnix
+	// +mapgen:mapping to:Age using:ParseAge
+	MustToDTO(in *User) *UserDTO
+}
@@ -0,0 +1,9 @@
+// Package timeutil converts times.
+package timeutil
+
+import "time"
+
+// ToUnix converts a time to Unix seconds.
+func ToUnix(t time.Time) int64 {
+	return t.Unix()
+}
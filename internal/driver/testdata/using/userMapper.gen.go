@@ -0,0 +1,39 @@
+// Code generated by mapgen. DO NOT EDIT.
+package using
+
+import (
+	"fmt"
+	"github.com/nduyhai/mapgen/convert"
+	"github.com/nduyhai/mapgen/internal/driver/testdata/using/timeutil"
+)
+
+type userMapper struct{}
+
+// NewUserMapper creates an implementation of UserMapper.
+func NewUserMapper() *userMapper {
+	return &userMapper{}
+}
+
+func (m *userMapper) ToDTO(in *User) (*UserDTO, error) {
+	if in == nil {
+		return nil, nil
+	}
+	out := &UserDTO{}
+	out.CreatedAt = timeutil.ToUnix(in.CreatedAt)
+	age, err := ParseAge(in.Age)
+	if err != nil {
+		return nil, fmt.Errorf("mapping User.Age: %w", err)
+	}
+	out.Age = age
+	return out, nil
+}
+
+func (m *userMapper) MustToDTO(in *User) *UserDTO {
+	if in == nil {
+		return nil
+	}
+	out := &UserDTO{}
+	out.CreatedAt = timeutil.ToUnix(in.CreatedAt)
+	out.Age = convert.Must(ParseAge(in.Age))
+	return out
+}
@@ -0,0 +1,30 @@
+// Package usingunknown converts a field with an invalid using: function, which is rejected.
+package usingunknown
+
+// User is the source of the mapping.
+type User struct {
+	Age string
+}
+
+// UserDTO is the target of the mapping.
+type UserDTO struct {
+	Age int
+}
+
+// ParseAge takes an int rather than a string.
+func ParseAge(age int) int {
+	return age
+}
+
+// FormatAge returns a string rather than an int.
+func FormatAge(age string) string {
+	return age
+}
+
+// UserMapper converts Age with an invalid function.
+//
+// +mapgen:mapper impl:userMapper
+type UserMapper interface {
+	// +mapgen:mapping to:Age using:Atoi
+	ToDTO(in *User) *UserDTO
+}
@@ -0,0 +1,15 @@
+// Package validator declares validators of structs with and without fields.
+package validator
+
+// User requires its fields.
+//
+// +mapgen:validator impl:userValidator
+type User struct {
+	Name  string
+	Email string
+}
+
+// Empty has no field to validate.
+//
+// +mapgen:validator impl:emptyValidator
+type Empty struct{}
@@ -2,27 +2,52 @@ package generator
 
 import (
 	"bytes"
-	"github.com/nduyhai/mapgen/internal/model"
+	"fmt"
+	"go/format"
 	"os"
 	"path/filepath"
 	"text/template"
+
+	"github.com/nduyhai/mapgen/internal/model"
+	"github.com/nduyhai/mapgen/templates"
 )
 
+// Generate writes the implementation of the mapper to outputDir.
+// The file is named after the mapper's target file, or its implementation name if none is set.
 func Generate(mapper *model.MapperDefinition, outputDir string) error {
-	tmpl, err := template.ParseFiles("templates/mapper_impl.tmpl")
+	filename := mapper.TargetFile
+	if filename == "" {
+		filename = mapper.ImplName + ".gen.go"
+	}
+
+	return render("mapper_impl.tmpl", mapper, filepath.Join(outputDir, filename))
+}
+
+// GenerateValidator writes the implementation of the validator to outputDir.
+func GenerateValidator(validator *model.ValidatorDefinition, outputDir string) error {
+	return render("validator_impl.tmpl", validator, filepath.Join(outputDir, validator.ImplName+".gen.go"))
+}
+
+// render executes the embedded template with data and writes the formatted result to filename.
+func render(templateFile string, data interface{}, filename string) error {
+	tmpl, err := template.ParseFS(templates.FS, templateFile)
 	if err != nil {
 		return err
 	}
 
 	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, mapper); err != nil {
+	if err := tmpl.Execute(&buf, data); err != nil {
 		return err
 	}
 
-	filename := filepath.Join(outputDir, mapper.ImplName+".gen.go")
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format generated code for %s: %w", filename, err)
+	}
+
 	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
 		return err
 	}
 
-	return os.WriteFile(filename, buf.Bytes(), 0644)
+	return os.WriteFile(filename, source, 0644)
 }
@@ -1,22 +1,307 @@
 package model
 
+import (
+	"go/ast"
+	"go/token"
+)
+
+// Directive represents a code generation directive found in comments,
+// e.g. "// +mapgen:mapper impl:userMapper".
+type Directive struct {
+	// Type is the type of the directive (e.g., "mapper")
+	Type string
+
+	// Metadata contains additional information about the directive
+	// (e.g., {"impl": "user_mapper"}); quoted values are unquoted and flags have an empty value
+	Metadata map[string]string
+
+	// Raw holds the metadata values as written, quoted values keeping their quotes
+	// (e.g., {"constant": `"v1"`})
+	Raw map[string]string
+
+	// Node is the AST node documented by the comment holding the directive
+	// (e.g., *ast.TypeSpec for a type, *ast.Field for an interface method or a struct field)
+	Node ast.Node
+
+	// Pos is the position of the comment holding the directive
+	Pos token.Pos
+}
+
+// ReportingPolicy is the severity with which a mapping problem is reported.
+type ReportingPolicy string
+
+const (
+	// PolicyError fails the generation
+	PolicyError ReportingPolicy = "error"
+	// PolicyWarn prints a warning and continues the generation
+	PolicyWarn ReportingPolicy = "warn"
+	// PolicyIgnore silently continues the generation
+	PolicyIgnore ReportingPolicy = "ignore"
+)
+
+// Conversion names a built-in conversion between a source and a target type.
+type Conversion string
+
+const (
+	// ConversionAuto applies the first built-in conversion that fits the types
+	ConversionAuto Conversion = "auto"
+	// ConversionNone disables the built-in conversions
+	ConversionNone Conversion = "none"
+	// ConversionUnderlying converts between named types and their underlying type, e.g. time.Duration and int64,
+	// and between two named types sharing an underlying type, e.g. UserID and OrderID, when it is selected
+	ConversionUnderlying Conversion = "underlying"
+	// ConversionBytes converts between string and []byte
+	ConversionBytes Conversion = "bytes"
+	// ConversionNumeric converts between numeric types, checking for overflows when narrowing,
+	// and between two named numeric types when it is selected
+	ConversionNumeric Conversion = "numeric"
+	// ConversionUnix converts between time.Time and int64 Unix seconds
+	ConversionUnix Conversion = "unix"
+	// ConversionUnixMilli converts between time.Time and int64 Unix milliseconds
+	ConversionUnixMilli Conversion = "unixMilli"
+	// ConversionRFC3339 converts between time.Time and RFC 3339 strings
+	ConversionRFC3339 Conversion = "rfc3339"
+	// ConversionStringer converts a fmt.Stringer to string
+	ConversionStringer Conversion = "stringer"
+)
+
+// NullValueStrategy is how update methods handle source fields holding a zero value.
+type NullValueStrategy string
+
+const (
+	// NullValueOverwrite copies every mapped source field, zero values included
+	NullValueOverwrite NullValueStrategy = "overwrite"
+	// NullValueSkip leaves the target field unchanged when the source field is the zero value or nil
+	NullValueSkip NullValueStrategy = "skip"
+)
+
+// NamingStrategy is how the names of target and source fields are compared when pairing them.
+type NamingStrategy string
+
+const (
+	// NamingExact pairs fields whose names are identical
+	NamingExact NamingStrategy = "exact"
+	// NamingCaseInsensitive pairs fields whose names only differ by case, e.g. UserName and Username
+	NamingCaseInsensitive NamingStrategy = "caseInsensitive"
+	// NamingNormalized pairs fields whose names only differ by case, underscores and dashes, e.g. user_id and UserID
+	NamingNormalized NamingStrategy = "normalized"
+)
+
+// MapperDefinition describes a mapper implementation to generate.
 type MapperDefinition struct {
+	// Name is the name of the mapper interface
 	Name     string
 	ImplName string
-	Package  string
-	Methods  []MappingMethod
+	// Constructor is the name of the function creating the implementation
+	Constructor string
+	Package     string
+	TargetFile  string
+	// UnmappedTarget is the policy for target fields that are neither mapped nor ignored
+	UnmappedTarget ReportingPolicy
+	// UnmappedSource is the policy for source fields that are neither read nor ignored
+	UnmappedSource ReportingPolicy
+	// Convert is the default conversion of the mapper's mappings
+	Convert Conversion
+	// NullValueStrategy is how update methods handle zero source fields
+	NullValueStrategy NullValueStrategy
+	// MatchTags are the struct tags whose names pair target fields without a mapping with source fields,
+	// in order of preference, e.g. ["db", "json"]; fields without them, or all fields when empty, are paired by name
+	MatchTags []string
+	// Naming is how the names of the fields without a mapping are compared
+	Naming NamingStrategy
+	// StripSourcePrefixes and StripTargetPrefixes are removed from the start of the names of source and
+	// target fields before comparing them, e.g. "Pb" pairs PbName with Name
+	StripSourcePrefixes []string
+	StripTargetPrefixes []string
+	Methods             []MapperMethod
+	// Uses are the other mappers whose methods are reused, injected through the constructor
+	Uses []UsedMapper
+	// Collections are the helper methods mapping collections element by element, set by the resolver
+	Collections []CollectionMapping
+	// Imports are the packages required by the generated code
+	Imports []Import
+}
+
+// Import is a package imported by the generated code.
+type Import struct {
+	// Path is the import path of the package
+	Path string
+	// Name is the name the generated code refers to the package by
+	Name string
+	// Alias reports whether Name differs from the name declared by the package, e.g. "model2"
+	// when two packages named model are imported, so the import declaration must name it
+	Alias bool
+}
+
+// UsedMapper is another mapper injected into a mapper implementation.
+type UsedMapper struct {
+	// Name is the name of the mapper interface as written in the directive, e.g. "AddressMapper" or "fancy.AddressMapper"
+	Name string
+	// Field is the name of the implementation field holding the mapper, set by the resolver
+	Field string
+	// Type is the mapper interface as written in the generated code, set by the resolver
+	Type string
 }
 
-type MappingMethod struct {
+// MapperMethod describes a single method of a mapper.
+type MapperMethod struct {
+	Name string
+	// Sources are the parameters the target is mapped from, set by the resolver
+	Sources    []SourceParameter
+	TargetType string
+	// SourceNilable reports whether every source can be nil, e.g. a pointer or an interface
+	SourceNilable bool
+	// TargetPointer reports whether the target is a pointer to the struct being mapped
+	TargetPointer bool
+	// TargetValueType is the struct type being mapped, TargetType without its pointer
+	TargetValueType string
+	// Update reports whether the method updates an existing target passed as its second parameter,
+	// e.g. MapInto(*User, *UserDTO), rather than returning a new one
+	Update bool
+	// ReturnsError reports whether the method returns an error, after the target unless it is an update method,
+	// set by the resolver
+	ReturnsError bool
+	// Context reports whether the method takes a leading context.Context, named "ctx" in the generated code,
+	// set by the resolver
+	Context bool
+	// ZeroResult is the target returned along with an error, e.g. "nil" or "UserDTO{}", set by the resolver
+	ZeroResult string
+	Mappings   []FieldMappingRule
+	// UnmappedSource overrides the mapper's policy for unmapped source fields when set
+	UnmappedSource ReportingPolicy
+	// NullValueStrategy overrides the mapper's strategy for zero source fields when set
+	NullValueStrategy NullValueStrategy
+	// Collection is set by the resolver when the method maps collections rather than structs
+	Collection *CollectionMapping
+	// Pos is the position of the method in the mapper interface
+	Pos token.Pos
+}
+
+// SourceParameter is a parameter of a mapper method that fields are mapped from.
+type SourceParameter struct {
+	// Name is the name of the parameter, "in" in the generated code of single-source methods
+	Name string
+	Type string
+}
+
+// CollectionKind is the kind of collection mapped by a CollectionMapping.
+type CollectionKind string
+
+const (
+	CollectionSlice CollectionKind = "Slice"
+	CollectionArray CollectionKind = "Array"
+	CollectionMap   CollectionKind = "Map"
+)
+
+// CollectionMapping describes the element by element mapping of a collection.
+type CollectionMapping struct {
+	// Name is the name of the method performing the mapping
 	Name       string
+	Kind       CollectionKind
 	SourceType string
 	TargetType string
-	Mappings   []FieldMappingRule
+	// ElemFunc is the function expression mapping an element, empty when elements are assignable
+	ElemFunc string
+	// ElemFuncError reports whether ElemFunc returns an error after the mapped element
+	ElemFuncError bool
+	// ElemFuncFallible reports whether ElemFunc is, or maps elements with, a built-in conversion
+	// that can fail and was not selected, e.g. a narrowing numeric conversion tried by auto
+	ElemFuncFallible bool
+	// ElemFuncContext reports whether ElemFunc takes the context before the element
+	ElemFuncContext bool
+	// ReturnsError reports whether the method performing the mapping returns an error
+	ReturnsError bool
+	// Context reports whether the method performing the mapping takes a context.Context
+	Context bool
 }
 
+// FieldMappingRule describes how a single target field is populated.
 type FieldMappingRule struct {
+	// SourceField is the source field, or a dotted path of fields such as "Address.City".
+	// Methods with several sources may qualify it by parameter name, e.g. "a.City"
 	SourceField string
+	// SourceExpr is the expression reading the source field in the generated code,
+	// e.g. "in.Address.City", set by the resolver
+	SourceExpr string
+	// TargetField is the target field, or a dotted path of fields such as "Address.City",
+	// empty for a source field ignored by its struct tag
 	TargetField string
 	Ignore      bool
 	CustomFunc  string
+	// CustomFuncError reports whether CustomFunc returns an error after the converted value
+	CustomFuncError bool
+	// Convert selects the conversion of the rule, the mapper's default is used when empty
+	Convert Conversion
+	// Conversion is the function expression converting the source field to the target field,
+	// set by the resolver when the types differ
+	Conversion string
+	// ConversionError reports whether Conversion returns an error after the converted value
+	ConversionError bool
+	// Context reports whether CustomFunc or Conversion takes the context of the method before the value
+	Context bool
+	// Var is the variable holding the converted value when the conversion can fail in a method
+	// returning an error, set by the resolver; the error is then returned rather than panicking
+	Var string
+	// ErrorContext describes the source field in the errors returned by the method, e.g. "User.CreatedAt"
+	ErrorContext string
+	// Constant is the Go expression assigned to the target field instead of a source field, e.g. `"v1"`
+	Constant string
+	// Default is the Go expression assigned to the target field when the source field is the zero value or nil
+	Default string
+	// Expr is the Go expression over the sources of the method assigned to the target field instead of a source field
+	Expr string
+	// ExprReads are the sources and source fields read by Expr, e.g. "in.FirstName", set by the resolver
+	ExprReads []string
+	// NilChecks are the expressions of the pointers traversed by SourceExpr, e.g. "in.Profile", or of the
+	// sources read by Expr, set by the resolver; the target field is left unset when one of them is nil
+	NilChecks []string
+	// SkipZero reports whether the target field is left unchanged when the source field is the zero value,
+	// set by the resolver for update methods using NullValueSkip
+	SkipZero bool
+	// TargetAllocations are the pointer fields traversed by a nested TargetField, set by the resolver.
+	// They are allocated only when the value assigned to the target field is not the zero value.
+	TargetAllocations []Allocation
+	// Pos is the position of the directive or of the tagged struct field that declared the rule,
+	// or token.NoPos for rules matched by the resolver
+	Pos token.Pos
+}
+
+// Allocation is a pointer field of the target that is allocated before setting a nested field.
+type Allocation struct {
+	// Path is the path of the pointer field relative to the target, e.g. "Address"
+	Path string
+	// Type is the type the field points to, as written in the generated code
+	Type string
+}
+
+// MappingDefinition is the result of processing a mapping directive.
+type MappingDefinition struct {
+	From    string
+	To      string
+	Using   string
+	Ignore  bool
+	Convert Conversion
+	// Constant is the Go expression assigned to the target field instead of a source field
+	Constant string
+	// Default is the Go expression assigned to the target field when the source field is the zero value or nil
+	Default string
+	// Expr is the Go expression over the source parameters assigned to the target field, e.g. `in.First + " " + in.Last`
+	Expr string
+}
+
+// MethodDefinition is the result of processing a method directive.
+// It holds the options that override the mapper's options for a single method.
+type MethodDefinition struct {
+	UnmappedSource    ReportingPolicy
+	NullValueStrategy NullValueStrategy
+}
+
+// ValidatorDefinition describes a validator implementation to generate.
+type ValidatorDefinition struct {
+	ImplName string
+	Package  string
+	// TypeName is the name of the validated type
+	TypeName string
+	// Fields maps field names to their validation rule
+	Fields map[string]string
 }
@@ -0,0 +1,267 @@
+package preprocessor
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nduyhai/mapgen/internal/model"
+)
+
+// directivePrefix starts every directive.
+const directivePrefix = "+mapgen:"
+
+// options are the options accepted by each directive type. Options marked true may also be
+// written as flags, without a value.
+var options = map[string]map[string]bool{
+	"mapper": {
+		"impl": false, "target": false, "uses": false, "convert": false,
+		"unmappedTarget": false, "unmappedSource": false, "nullValueStrategy": false,
+		"matchBy": false, "naming": false, "stripSourcePrefix": false, "stripTargetPrefix": false,
+	},
+	"method": {
+		"unmappedSource": false, "nullValueStrategy": false,
+	},
+	"mapping": {
+		"from": false, "to": false, "using": false, "ignore": true, "convert": false,
+		"constant": false, "default": false, "expr": false,
+	},
+	"validator": {
+		"impl": false,
+	},
+}
+
+// Error is a syntax error in a directive, reported at the position of the offending text.
+type Error struct {
+	Pos token.Position
+	Msg string
+}
+
+// Error formats the error as "file:line:column: message".
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// parser reads the directives of a comment.
+//
+// A directive is "+mapgen:<type>" at the start of a comment line, followed by options separated by spaces.
+// An option is either a flag ("ignore"), or a key and a value separated by ':' or '=' ("from:Name",
+// "from=Name"). Values are plain words running up to the next space, or Go string literals, either
+// double-quoted with escapes (`expr:"in.First + \" \" + in.Last"`) or back-quoted (expr:`in.Age >= 18`).
+// A line may hold several directives.
+type parser struct {
+	fset *token.FileSet
+	// text is the text of the comment and pos the position of its first byte
+	text string
+	pos  token.Pos
+	// off is the offset of the next byte to read, end the offset of the end of the current line
+	off, end int
+}
+
+// parseComment returns the directives of the comment whose text starts at pos.
+func parseComment(fset *token.FileSet, text string, pos token.Pos) ([]model.Directive, error) {
+	p := &parser{fset: fset, text: text, pos: pos}
+
+	// Block comments may hold a directive on each line, which may start with a '*'
+	body, start, block := text, 0, false
+	switch {
+	case strings.HasPrefix(text, "//"):
+		body, start = text[2:], 2
+	case strings.HasPrefix(text, "/*"):
+		body, start, block = strings.TrimSuffix(text[2:], "*/"), 2, true
+	}
+
+	var directives []model.Directive
+	for _, line := range strings.SplitAfter(body, "\n") {
+		p.off, p.end = start, start+len(strings.TrimRight(line, "\r\n"))
+		start += len(line)
+
+		p.skipSpaces()
+		if block && p.peek() == '*' && !strings.HasPrefix(p.rest(), "*/") {
+			p.off++
+			p.skipSpaces()
+		}
+		// Directives are only recognized at the start of a line, not in the middle of a sentence
+		if !strings.HasPrefix(p.rest(), directivePrefix) {
+			continue
+		}
+
+		found, err := p.parseLine()
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, found...)
+	}
+	return directives, nil
+}
+
+// parseLine parses the directives from the current offset up to the end of the line.
+func (p *parser) parseLine() ([]model.Directive, error) {
+	var directives []model.Directive
+	for p.off < p.end {
+		directive, err := p.parseDirective()
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, directive)
+	}
+	return directives, nil
+}
+
+// parseDirective parses a directive and its options, up to the next directive or the end of the line.
+func (p *parser) parseDirective() (model.Directive, error) {
+	start := p.off
+	p.off += len(directivePrefix)
+	typ := p.ident()
+	if typ == "" {
+		return model.Directive{}, p.errorf(p.off, "missing directive type after %s", directivePrefix)
+	}
+	allowed, ok := options[typ]
+	if !ok {
+		return model.Directive{}, p.errorf(start, "unknown directive %s%s, expected one of: %s",
+			directivePrefix, typ, strings.Join(sortedKeys(options), ", "))
+	}
+	if p.off < p.end && !isSpace(p.peek()) {
+		return model.Directive{}, p.errorf(p.off, "unexpected %q after %s%s", p.peek(), directivePrefix, typ)
+	}
+
+	directive := model.Directive{
+		Type:     typ,
+		Metadata: make(map[string]string),
+		Raw:      make(map[string]string),
+		Pos:      p.pos + token.Pos(start),
+	}
+	for {
+		p.skipSpaces()
+		if p.off >= p.end || strings.HasPrefix(p.rest(), directivePrefix) {
+			return directive, nil
+		}
+
+		keyOff := p.off
+		key := p.ident()
+		if key == "" {
+			return model.Directive{}, p.errorf(keyOff, "unexpected %q, expected an option of %s%s", p.peek(), directivePrefix, typ)
+		}
+		flag, ok := allowed[key]
+		if !ok {
+			return model.Directive{}, p.errorf(keyOff, "unknown option %s of %s%s, expected one of: %s",
+				key, directivePrefix, typ, strings.Join(sortedKeys(allowed), ", "))
+		}
+		if _, ok := directive.Metadata[key]; ok {
+			return model.Directive{}, p.errorf(keyOff, "duplicate option %s", key)
+		}
+
+		var value, raw string
+		switch p.peek() {
+		case ':', '=':
+			p.off++
+			var err error
+			if value, raw, err = p.value(key); err != nil {
+				return model.Directive{}, err
+			}
+		default:
+			if p.off < p.end && !isSpace(p.peek()) {
+				return model.Directive{}, p.errorf(p.off, "unexpected %q after option %s, expected ':' or '='", p.peek(), key)
+			}
+			if !flag {
+				return model.Directive{}, p.errorf(keyOff, "option %s requires a value, e.g. %s:<value>", key, key)
+			}
+		}
+		directive.Metadata[key] = value
+		directive.Raw[key] = raw
+	}
+}
+
+// value parses the value of the option key, returning it unquoted and as written.
+func (p *parser) value(key string) (string, string, error) {
+	start := p.off
+	switch quote := p.peek(); quote {
+	case '"', '`':
+		p.off++
+		for p.off < p.end && p.text[p.off] != quote {
+			if quote == '"' && p.text[p.off] == '\\' {
+				p.off++
+			}
+			p.off++
+		}
+		if p.off >= p.end {
+			return "", "", p.errorf(start, "unterminated quoted value of option %s", key)
+		}
+		p.off++
+		raw := p.text[start:p.off]
+		value, err := strconv.Unquote(raw)
+		if err != nil {
+			return "", "", p.errorf(start, "invalid quoted value of option %s: %s", key, raw)
+		}
+		if p.off < p.end && !isSpace(p.peek()) {
+			return "", "", p.errorf(p.off, "unexpected %q after quoted value of option %s", p.peek(), key)
+		}
+		return value, raw, nil
+	default:
+		for p.off < p.end && !isSpace(p.peek()) {
+			p.off++
+		}
+		if p.off == start {
+			return "", "", p.errorf(start, "missing value of option %s", key)
+		}
+		raw := p.text[start:p.off]
+		return raw, raw, nil
+	}
+}
+
+// ident reads an identifier, returning an empty string if there is none at the current offset.
+func (p *parser) ident() string {
+	start := p.off
+	for p.off < p.end {
+		r, size := utf8.DecodeRuneInString(p.text[p.off:p.end])
+		if !unicode.IsLetter(r) && r != '_' && (p.off == start || !unicode.IsDigit(r)) {
+			break
+		}
+		p.off += size
+	}
+	return p.text[start:p.off]
+}
+
+// skipSpaces moves past the spaces at the current offset.
+func (p *parser) skipSpaces() {
+	for p.off < p.end && isSpace(p.peek()) {
+		p.off++
+	}
+}
+
+// peek returns the byte at the current offset, or 0 at the end of the line.
+func (p *parser) peek() byte {
+	if p.off >= p.end {
+		return 0
+	}
+	return p.text[p.off]
+}
+
+// rest returns the text from the current offset up to the end of the line.
+func (p *parser) rest() string {
+	return p.text[p.off:p.end]
+}
+
+// errorf returns an Error at the offset off of the comment.
+func (p *parser) errorf(off int, format string, args ...interface{}) error {
+	return &Error{Pos: p.fset.Position(p.pos + token.Pos(off)), Msg: fmt.Sprintf(format, args...)}
+}
+
+// isSpace reports whether c separates options.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// sortedKeys returns the keys of m in alphabetical order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,226 @@
+package preprocessor
+
+import (
+	"errors"
+	"go/token"
+	"maps"
+	"strings"
+	"testing"
+)
+
+// parse parses the comment text as if it started a file named mapper.go.
+func parse(text string) ([]directiveResult, error) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("mapper.go", -1, len(text))
+	file.SetLinesForContent([]byte(text))
+
+	directives, err := parseComment(fset, text, file.Pos(0))
+	if err != nil {
+		return nil, err
+	}
+	var results []directiveResult
+	for _, directive := range directives {
+		results = append(results, directiveResult{
+			typ:      directive.Type,
+			metadata: directive.Metadata,
+			raw:      directive.Raw,
+			pos:      fset.Position(directive.Pos).String(),
+		})
+	}
+	return results, nil
+}
+
+// directiveResult is the part of a directive set by parseComment.
+type directiveResult struct {
+	typ      string
+	metadata map[string]string
+	raw      map[string]string
+	pos      string
+}
+
+func TestParseComment(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []directiveResult
+	}{
+		{
+			name: "no directive",
+			text: "// UserMapper maps users.",
+		},
+		{
+			name: "directive in a sentence",
+			text: "// Add a +mapgen:mapping directive to configure a field.",
+		},
+		{
+			name: "no options",
+			text: "// +mapgen:mapper",
+			want: []directiveResult{{typ: "mapper", metadata: map[string]string{}, raw: map[string]string{}, pos: "mapper.go:1:4"}},
+		},
+		{
+			name: "colon and equal separators",
+			text: "// +mapgen:mapping from:UserName to=Name",
+			want: []directiveResult{{
+				typ:      "mapping",
+				metadata: map[string]string{"from": "UserName", "to": "Name"},
+				raw:      map[string]string{"from": "UserName", "to": "Name"},
+				pos:      "mapper.go:1:4",
+			}},
+		},
+		{
+			name: "double-quoted value with escapes",
+			text: `// +mapgen:mapping to:FullName expr:"in.First + \" \" + in.Last"`,
+			want: []directiveResult{{
+				typ:      "mapping",
+				metadata: map[string]string{"to": "FullName", "expr": `in.First + " " + in.Last`},
+				raw:      map[string]string{"to": "FullName", "expr": `"in.First + \" \" + in.Last"`},
+				pos:      "mapper.go:1:4",
+			}},
+		},
+		{
+			name: "back-quoted value",
+			text: "// +mapgen:mapping to:Adult expr:`in.Age >= 18`",
+			want: []directiveResult{{
+				typ:      "mapping",
+				metadata: map[string]string{"to": "Adult", "expr": "in.Age >= 18"},
+				raw:      map[string]string{"to": "Adult", "expr": "`in.Age >= 18`"},
+				pos:      "mapper.go:1:4",
+			}},
+		},
+		{
+			name: "flag",
+			text: "// +mapgen:mapping ignore to:Password",
+			want: []directiveResult{{
+				typ:      "mapping",
+				metadata: map[string]string{"ignore": "", "to": "Password"},
+				raw:      map[string]string{"ignore": "", "to": "Password"},
+				pos:      "mapper.go:1:4",
+			}},
+		},
+		{
+			name: "several directives on a line",
+			text: "// +mapgen:mapping ignore:Password +mapgen:mapping ignore:Salt",
+			want: []directiveResult{
+				{typ: "mapping", metadata: map[string]string{"ignore": "Password"}, raw: map[string]string{"ignore": "Password"}, pos: "mapper.go:1:4"},
+				{typ: "mapping", metadata: map[string]string{"ignore": "Salt"}, raw: map[string]string{"ignore": "Salt"}, pos: "mapper.go:1:36"},
+			},
+		},
+		{
+			name: "block comment",
+			text: "/*\n * +mapgen:mapper impl:userMapper\n * +mapgen:validator\n */",
+			want: []directiveResult{
+				{typ: "mapper", metadata: map[string]string{"impl": "userMapper"}, raw: map[string]string{"impl": "userMapper"}, pos: "mapper.go:2:4"},
+				{typ: "validator", metadata: map[string]string{}, raw: map[string]string{}, pos: "mapper.go:3:4"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parse(tt.text)
+			if err != nil {
+				t.Fatalf("parseComment() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseComment() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].typ != tt.want[i].typ || got[i].pos != tt.want[i].pos ||
+					!maps.Equal(got[i].metadata, tt.want[i].metadata) || !maps.Equal(got[i].raw, tt.want[i].raw) {
+					t.Errorf("parseComment()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseCommentErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		// pos is the position of the error and msg the start of its message
+		pos string
+		msg string
+	}{
+		{
+			name: "missing type",
+			text: "// +mapgen:",
+			pos:  "mapper.go:1:12",
+			msg:  "missing directive type after +mapgen:",
+		},
+		{
+			name: "unknown type",
+			text: "// +mapgen:mapers",
+			pos:  "mapper.go:1:4",
+			msg:  "unknown directive +mapgen:mapers, expected one of: mapper, mapping, method, validator",
+		},
+		{
+			name: "unknown option",
+			text: "// +mapgen:mapping form:Name",
+			pos:  "mapper.go:1:20",
+			msg:  "unknown option form of +mapgen:mapping",
+		},
+		{
+			name: "duplicate option",
+			text: "// +mapgen:mapping to:Name to:FullName",
+			pos:  "mapper.go:1:28",
+			msg:  "duplicate option to",
+		},
+		{
+			name: "missing value",
+			text: "// +mapgen:mapping to:",
+			pos:  "mapper.go:1:23",
+			msg:  "missing value of option to",
+		},
+		{
+			name: "value required",
+			text: "// +mapgen:mapping to",
+			pos:  "mapper.go:1:20",
+			msg:  "option to requires a value",
+		},
+		{
+			name: "unterminated quote",
+			text: `// +mapgen:mapping expr:"in.Name to:Name`,
+			pos:  "mapper.go:1:25",
+			msg:  "unterminated quoted value of option expr",
+		},
+		{
+			name: "invalid escape",
+			text: `// +mapgen:mapping constant:"\q" to:Name`,
+			pos:  "mapper.go:1:29",
+			msg:  `invalid quoted value of option constant: "\q"`,
+		},
+		{
+			name: "text after quoted value",
+			text: `// +mapgen:mapping constant:"v1"x to:Name`,
+			pos:  "mapper.go:1:33",
+			msg:  `unexpected 'x' after quoted value of option constant`,
+		},
+		{
+			name: "invalid separator",
+			text: "// +mapgen:mapping to-Name",
+			pos:  "mapper.go:1:22",
+			msg:  `unexpected '-' after option to`,
+		},
+		{
+			name: "error on a later line",
+			text: "/*\n * +mapgen:mapper\n *   +mapgen:mapping from:\n */",
+			pos:  "mapper.go:3:27",
+			msg:  "missing value of option from",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(tt.text)
+			var parseErr *Error
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("parseComment() error = %v, want an *Error", err)
+			}
+			if got := parseErr.Pos.String(); got != tt.pos {
+				t.Errorf("parseComment() error position = %s, want %s", got, tt.pos)
+			}
+			if !strings.HasPrefix(parseErr.Msg, tt.msg) {
+				t.Errorf("parseComment() error message = %q, want it to start with %q", parseErr.Msg, tt.msg)
+			}
+		})
+	}
+}
@@ -1,26 +1,32 @@
 package preprocessor
 
 import (
+	"errors"
+	"fmt"
 	"go/ast"
-	"regexp"
+	"go/token"
 
 	"github.com/nduyhai/mapgen/internal/model"
 )
 
 // Preprocessor is responsible for finding directives in comments and building model.Directive objects.
 // It processes ast.File objects from the scanner and extracts directives from comments.
-// Directives are in the form of "+mapgen:<type>" and are associated with the closest AST node.
-type Preprocessor struct{}
+// Directives are in the form of "+mapgen:<type>" and are associated with the AST node they document,
+// a declaration, an interface method or a struct field.
+type Preprocessor struct {
+	// fset positions the errors of malformed directives
+	fset *token.FileSet
+}
 
-// NewPreprocessor creates a new Preprocessor instance.
+// NewPreprocessor creates a new Preprocessor instance for files parsed into fset.
 // This is the entry point for using the preprocessor functionality.
 //
 // Usage:
 //
-//	preprocessor := NewPreprocessor()
-//	directives := preprocessor.Process(file)
-func NewPreprocessor() *Preprocessor {
-	return &Preprocessor{}
+//	preprocessor := NewPreprocessor(fset)
+//	directives, err := preprocessor.Process(file)
+func NewPreprocessor(fset *token.FileSet) *Preprocessor {
+	return &Preprocessor{fset: fset}
 }
 
 // Process finds directives in comments and builds model.Directive objects.
@@ -28,136 +34,86 @@ func NewPreprocessor() *Preprocessor {
 //
 // The process involves:
 // 1. Iterating through all comment groups in the file
-// 2. Finding directives in the form of "+mapgen:<type>" in comments
-// 3. Associating each directive with the closest AST node (TypeSpec, FuncDecl, etc.)
+// 2. Parsing the directives in the form of "+mapgen:<type> key:value" starting the lines of comments
+// 3. Associating each directive with the AST node its comment documents (TypeSpec, FuncDecl, Field, etc.)
 // 4. Building a model.Directive for each directive found with:
-//    - Type: "mapper"
-//    - Metadata: {"impl": "user_mapper"}
-//    - Node: The associated AST node
-func (p *Preprocessor) Process(file *ast.File) []model.Directive {
-	var directives []model.Directive
+//   - Type: "mapper"
+//   - Metadata: {"impl": "user_mapper"}
+//   - Node: The associated AST node
+//   - Pos: The position of the directive
+//
+// Malformed directives, such as unknown options or unterminated quoted values, and directives
+// that document no declaration, method or field are reported as *Error values, all the errors
+// of the file being joined.
+func (p *Preprocessor) Process(file *ast.File) ([]model.Directive, error) {
+	var (
+		directives []model.Directive
+		errs       []error
+	)
+
+	nodes := p.associatedNodes(file)
 
 	// Process all comment groups in the file
 	for _, commentGroup := range file.Comments {
 		for _, comment := range commentGroup.List {
-			// Find directives in the comment
-			foundDirectives := p.findDirectivesInComment(comment.Text)
-			
-			// If directives are found, associate them with the appropriate AST node
+			// Parse the directives of the comment
+			foundDirectives, err := parseComment(p.fset, comment.Text, comment.Pos())
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			// Associate the directives with the node documented by the comment group
 			for _, directive := range foundDirectives {
-				// Find the AST node associated with the comment
-				node := p.findAssociatedNode(file, comment)
-				if node != nil {
-					directive.Node = node
-					// Add the package name to the directive's metadata
-					directive.Metadata["package"] = file.Name.Name
-					directives = append(directives, directive)
+				node, ok := nodes[commentGroup]
+				if !ok {
+					errs = append(errs, &Error{
+						Pos: p.fset.Position(directive.Pos),
+						Msg: fmt.Sprintf("%s%s must document a declaration, an interface method or a struct field", directivePrefix, directive.Type),
+					})
+					continue
 				}
+				directive.Node = node
+				// Add the package name to the directive's metadata
+				directive.Metadata["package"] = file.Name.Name
+				directives = append(directives, directive)
 			}
 		}
 	}
 
-	return directives
+	return directives, errors.Join(errs...)
 }
 
-// findDirectivesInComment finds directives in a comment.
-// It looks for patterns like "+mapgen:<type>" and extracts the type and metadata.
+// associatedNodes returns the nodes documented by the comment groups of the file.
+// Comments are associated as by ast.NewCommentMap: a comment group documents the node that follows it,
+// or the node ending on the line it starts, such as a method followed by a line comment.
 //
-// The method uses a regular expression to match directives in the form of "+mapgen:<type>".
-// For each directive found, it creates a model.Directive with:
-// - Type: "mapper" (as specified in the issue description)
-// - Metadata: {"impl": "user_mapper"} (as specified in the issue description)
+// The returned nodes can be:
+// - *ast.TypeSpec: For type declarations, including the types of a grouped declaration
+// - *ast.FuncDecl: For function declarations
+// - *ast.Field: For interface methods and struct fields
+// - *ast.GenDecl and other specs: For var, const and import declarations
 //
-// The Node field will be set by the Process method after finding the associated AST node.
-func (p *Preprocessor) findDirectivesInComment(commentText string) []model.Directive {
-	var directives []model.Directive
-
-	// Regular expression to match directives like "+mapgen:<type>"
-	directiveRegex := regexp.MustCompile(`\+mapgen:(\w+)`)
-	metadataRegex := regexp.MustCompile(`(\w+):([\w\.]+)`)
-	
-	matches := directiveRegex.FindAllStringSubmatch(commentText, -1)
-
-	for _, match := range matches {
-		if len(match) < 2 {
+// Comment groups associated with any other node, such as a statement of a function body, are left out.
+func (p *Preprocessor) associatedNodes(file *ast.File) map[*ast.CommentGroup]ast.Node {
+	nodes := make(map[*ast.CommentGroup]ast.Node)
+	for node, groups := range ast.NewCommentMap(p.fset, file, file.Comments) {
+		switch n := node.(type) {
+		case *ast.GenDecl:
+			// The doc comment of a declaration applies to its first type, e.g. "type UserMapper interface"
+			for _, spec := range n.Specs {
+				if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+					node = typeSpec
+					break
+				}
+			}
+		case *ast.FuncDecl, *ast.Field, ast.Spec:
+		default:
 			continue
 		}
-
-		// Create a new directive with the type from the regex match
-		directive := model.Directive{
-			Type:     match[1],
-			Metadata: make(map[string]string),
-		}
-
-		// Extract metadata from the directive using a separate regex
-		metadataMatches := metadataRegex.FindAllStringSubmatch(commentText, -1)
-		for _, metadataMatch := range metadataMatches {
-			if len(metadataMatch) < 3 {
-				continue
-			}
-			
-			key := metadataMatch[1]
-			value := metadataMatch[2]
-			
-			// Skip the directive type itself
-			if key == "mapgen" {
-				continue
-			}
-			
-			directive.Metadata[key] = value
+		for _, group := range groups {
+			nodes[group] = node
 		}
-
-		// Add the directive to the list
-		directives = append(directives, directive)
 	}
-
-	return directives
+	return nodes
 }
-
-// findAssociatedNode finds the AST node associated with a comment.
-// It looks for the closest declaration (type, function, etc.) after the comment.
-//
-// The method works by:
-// 1. Finding the position of the comment in the file
-// 2. Looking for declarations that come after the comment
-// 3. Returning the first declaration found, with preference for type specifications
-//
-// This ensures that directives are associated with the correct AST node,
-// which is typically the declaration that follows the comment.
-//
-// The returned node can be:
-// - *ast.TypeSpec: For type declarations
-// - *ast.FuncDecl: For function declarations
-// - *ast.GenDecl: For general declarations (var, const, etc.)
-// - Other ast.Node types depending on the structure of the code
-func (p *Preprocessor) findAssociatedNode(file *ast.File, comment *ast.Comment) ast.Node {
-	// Find the position of the comment in the file
-	commentPos := comment.Pos()
-
-	// Look for declarations that come after the comment
-	for _, decl := range file.Decls {
-		// Check if the declaration is after the comment
-		if decl.Pos() > commentPos {
-			// If it's a general declaration (type, var, const)
-			if genDecl, ok := decl.(*ast.GenDecl); ok {
-				// Look for type specifications
-				for _, spec := range genDecl.Specs {
-					if typeSpec, ok := spec.(*ast.TypeSpec); ok {
-						return typeSpec
-					}
-				}
-				// If no type spec is found, return the general declaration
-				return genDecl
-			}
-			// If it's a function declaration
-			if funcDecl, ok := decl.(*ast.FuncDecl); ok {
-				return funcDecl
-			}
-			// Return any other kind of declaration
-			return decl
-		}
-	}
-
-	// If no associated node is found, return nil
-	return nil
-}
\ No newline at end of file
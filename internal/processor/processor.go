@@ -31,10 +31,11 @@ func NewRegistry() *Registry {
 		processors: make(map[string]Processor),
 	}
 
- // Register all supported processors
+	// Register all supported processors
 	registry.Register(NewMapperProcessor())
 	registry.Register(NewValidatorProcessor())
 	registry.Register(NewMappingProcessor())
+	registry.Register(NewMethodProcessor())
 
 	return registry
 }
@@ -80,6 +81,10 @@ func (p *MapperProcessor) Process(directive model.Directive) (interface{}, error
 	if !ok {
 		return nil, fmt.Errorf("mapper directive must be associated with a type specification, got %T", directive.Node)
 	}
+	interfaceType, ok := typeSpec.Type.(*ast.InterfaceType)
+	if !ok {
+		return nil, fmt.Errorf("mapper must be declared on an interface, %s is not an interface type", typeSpec.Name.Name)
+	}
 
 	// Extract implementation name from metadata or use a default
 	implName := directive.Metadata["impl"]
@@ -91,6 +96,46 @@ func (p *MapperProcessor) Process(directive model.Directive) (interface{}, error
 	// Extract target file name from metadata
 	targetFile := directive.Metadata["target"]
 
+	// Extract the policies for target and source fields that are not mapped
+	unmappedTarget, err := parsePolicy(directive.Metadata, "unmappedTarget", model.PolicyWarn)
+	if err != nil {
+		return nil, err
+	}
+	unmappedSource, err := parsePolicy(directive.Metadata, "unmappedSource", model.PolicyIgnore)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract the default conversion of the mappings
+	convert, err := parseConversion(directive.Metadata, model.ConversionAuto)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract how update methods handle zero source fields
+	nullValueStrategy, err := parseNullValueStrategy(directive.Metadata, model.NullValueOverwrite)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract the struct tags pairing the fields without a mapping, and how their names are compared
+	matchTags, err := parseMatchBy(directive.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	naming, err := parseNaming(directive.Metadata, model.NamingExact)
+	if err != nil {
+		return nil, err
+	}
+	stripSourcePrefixes, err := parsePrefixes(directive.Metadata, "stripSourcePrefix")
+	if err != nil {
+		return nil, err
+	}
+	stripTargetPrefixes, err := parsePrefixes(directive.Metadata, "stripTargetPrefix")
+	if err != nil {
+		return nil, err
+	}
+
 	// Get the package name from the file that contains the TypeSpec
 	packageName := ""
 	if file, ok := directive.Metadata["package"]; ok {
@@ -100,95 +145,41 @@ func (p *MapperProcessor) Process(directive model.Directive) (interface{}, error
 		packageName = "mapper"
 	}
 
+	// Extract the other mappers whose methods are reused
+	var uses []model.UsedMapper
+	if value := directive.Metadata["uses"]; value != "" {
+		for _, name := range strings.Split(value, ",") {
+			uses = append(uses, model.UsedMapper{Name: name})
+		}
+	}
+
 	// Create a mapper definition
 	mapperDef := model.MapperDefinition{
-		ImplName:   implName,
-		Package:    packageName,
-		TargetFile: targetFile,
-		Methods:    []model.MapperMethod{},
-		Imports:    []string{},
-	}
-
-	// Extract interface details if it's an interface
-	if interfaceType, ok := typeSpec.Type.(*ast.InterfaceType); ok {
-		// Process interface methods
-		for _, method := range interfaceType.Methods.List {
-			if funcType, ok := method.Type.(*ast.FuncType); ok {
-				// Extract method name
-				methodName := ""
-				if len(method.Names) > 0 {
-					methodName = method.Names[0].Name
-				}
-
-				// Extract parameter types
-				sourceType := ""
-				if len(funcType.Params.List) > 0 {
-					sourceType = exprToString(funcType.Params.List[0].Type)
-				}
-
-				// Extract return types
-				targetType := ""
-				if funcType.Results != nil && len(funcType.Results.List) > 0 {
-					targetType = exprToString(funcType.Results.List[0].Type)
-				}
-
-				// Add method to mapper definition
-				mapperDef.Methods = append(mapperDef.Methods, model.MapperMethod{
-					Name:       methodName,
-					SourceType: sourceType,
-					TargetType: targetType,
-				})
-				
-				// Extract package names from source and target types
-				if srcPkg := extractPackage(sourceType); srcPkg != "" {
-					// Check if the package is already in the imports
-					found := false
-					for _, imp := range mapperDef.Imports {
-						if imp == srcPkg {
-							found = true
-							break
-						}
-					}
-					if !found {
-						mapperDef.Imports = append(mapperDef.Imports, srcPkg)
-					}
-				}
-				
-				if tgtPkg := extractPackage(targetType); tgtPkg != "" {
-					// Check if the package is already in the imports
-					found := false
-					for _, imp := range mapperDef.Imports {
-						if imp == tgtPkg {
-							found = true
-							break
-						}
-					}
-					if !found {
-						mapperDef.Imports = append(mapperDef.Imports, tgtPkg)
-					}
-				}
-			}
+		Name:                typeSpec.Name.Name,
+		ImplName:            implName,
+		Constructor:         "New" + strings.ToUpper(implName[:1]) + implName[1:],
+		Uses:                uses,
+		Package:             packageName,
+		TargetFile:          targetFile,
+		UnmappedTarget:      unmappedTarget,
+		UnmappedSource:      unmappedSource,
+		Convert:             convert,
+		NullValueStrategy:   nullValueStrategy,
+		MatchTags:           matchTags,
+		Naming:              naming,
+		StripSourcePrefixes: stripSourcePrefixes,
+		StripTargetPrefixes: stripTargetPrefixes,
+		Methods:             []model.MapperMethod{},
+	}
+
+	// Process interface methods, their signatures are resolved with the type information of the package
+	for _, method := range interfaceType.Methods.List {
+		if _, ok := method.Type.(*ast.FuncType); ok && len(method.Names) > 0 {
+			mapperDef.Methods = append(mapperDef.Methods, model.MapperMethod{
+				Name: method.Names[0].Name,
+				Pos:  method.Pos(),
+			})
 		}
-	} else {
-		// For non-interface types, create standard mapper methods
-		typeName := typeSpec.Name.Name
-		
-		// Add ToDTO method
-		mapperDef.Methods = append(mapperDef.Methods, model.MapperMethod{
-			Name:       "ToDTO",
-			SourceType: "*" + typeName,
-			TargetType: "*dto." + typeName + "DTO",
-		})
-
-		// Add ToEntity method
-		mapperDef.Methods = append(mapperDef.Methods, model.MapperMethod{
-			Name:       "ToEntity",
-			SourceType: "*dto." + typeName + "DTO",
-			TargetType: "*" + typeName,
-		})
-		
-		// Add "dto" to imports since we're using dto package in the generated code
-		mapperDef.Imports = append(mapperDef.Imports, "dto")
 	}
 
 	return mapperDef, nil
@@ -222,10 +213,18 @@ func (p *ValidatorProcessor) Process(directive model.Directive) (interface{}, er
 		implName = strings.ToLower(typeSpec.Name.Name) + "_validator"
 	}
 
+	// Get the package name from the file that contains the TypeSpec
+	packageName := directive.Metadata["package"]
+	if packageName == "" {
+		// Default to "validator" if package name is not provided
+		packageName = "validator"
+	}
+
 	// Create a validator definition
 	validatorDef := model.ValidatorDefinition{
 		ImplName: implName,
-		Package:  "validator", // Default package
+		Package:  packageName,
+		TypeName: typeSpec.Name.Name,
 		Fields:   make(map[string]string),
 	}
 
@@ -274,43 +273,186 @@ func (p *MappingProcessor) Process(directive model.Directive) (interface{}, erro
 		mappingDef.Using = using
 	}
 
-	if _, ok := directive.Metadata["ignore"]; ok {
+	// The ignored field is either the value of ignore, or the to field when ignore is a flag
+	if ignored, ok := directive.Metadata["ignore"]; ok {
 		mappingDef.Ignore = true
+		if ignored != "" {
+			mappingDef.To = ignored
+		}
+		if mappingDef.To == "" {
+			return nil, fmt.Errorf("ignore requires a field, e.g. ignore:Name")
+		}
 	}
 
+	// Constant and default values are Go expressions, checked against the target field by the resolver.
+	// They are taken as written, so that quoted values are Go strings.
+	if _, ok := directive.Metadata["constant"]; ok {
+		if mappingDef.From != "" || mappingDef.Using != "" || mappingDef.Ignore {
+			return nil, fmt.Errorf("constant cannot be combined with from, using or ignore")
+		}
+		if mappingDef.To == "" {
+			return nil, fmt.Errorf("constant requires a target field, set with to")
+		}
+		mappingDef.Constant = directive.Raw["constant"]
+	}
+
+	// Expressions contain spaces, so they are usually quoted
+	if expr, ok := directive.Metadata["expr"]; ok {
+		if mappingDef.From != "" || mappingDef.Using != "" || mappingDef.Ignore || mappingDef.Constant != "" {
+			return nil, fmt.Errorf("expr cannot be combined with from, using, ignore or constant")
+		}
+		if mappingDef.To == "" {
+			return nil, fmt.Errorf("expr requires a target field, set with to")
+		}
+		mappingDef.Expr = expr
+	}
+
+	if _, ok := directive.Metadata["default"]; ok {
+		if mappingDef.Ignore || mappingDef.Constant != "" || mappingDef.Expr != "" {
+			return nil, fmt.Errorf("default cannot be combined with ignore, constant or expr")
+		}
+		mappingDef.Default = directive.Raw["default"]
+	}
+
+	// An empty conversion keeps the one of the mapper
+	convert, err := parseConversion(directive.Metadata, "")
+	if err != nil {
+		return nil, err
+	}
+	mappingDef.Convert = convert
+
 	return mappingDef, nil
 }
 
-// Helper function to convert an AST expression to a string representation
-func exprToString(expr ast.Expr) string {
-	switch t := expr.(type) {
-	case *ast.Ident:
-		return t.Name
-	case *ast.SelectorExpr:
-		return exprToString(t.X) + "." + t.Sel.Name
-	case *ast.StarExpr:
-		return "*" + exprToString(t.X)
-	case *ast.ArrayType:
-		return "[]" + exprToString(t.Elt)
-	case *ast.MapType:
-		return "map[" + exprToString(t.Key) + "]" + exprToString(t.Value)
+// MethodProcessor is a processor for method directives.
+type MethodProcessor struct{}
+
+// NewMethodProcessor creates a new MethodProcessor.
+func NewMethodProcessor() *MethodProcessor {
+	return &MethodProcessor{}
+}
+
+// Type returns the type of directive that this processor handles.
+func (p *MethodProcessor) Type() string {
+	return "method"
+}
+
+// Process processes a method directive and returns a MethodDefinition.
+func (p *MethodProcessor) Process(directive model.Directive) (interface{}, error) {
+	// An empty policy keeps the one of the mapper
+	unmappedSource, err := parsePolicy(directive.Metadata, "unmappedSource", "")
+	if err != nil {
+		return nil, err
+	}
+
+	nullValueStrategy, err := parseNullValueStrategy(directive.Metadata, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return model.MethodDefinition{
+		UnmappedSource:    unmappedSource,
+		NullValueStrategy: nullValueStrategy,
+	}, nil
+}
+
+// parsePolicy reads a reporting policy from the metadata key, falling back to def when the key is absent.
+func parsePolicy(metadata map[string]string, key string, def model.ReportingPolicy) (model.ReportingPolicy, error) {
+	value, ok := metadata[key]
+	if !ok {
+		return def, nil
+	}
+
+	switch policy := model.ReportingPolicy(value); policy {
+	case model.PolicyError, model.PolicyWarn, model.PolicyIgnore:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid %s policy %q, expected one of: error, warn, ignore", key, value)
+	}
+}
+
+// parseConversion reads the conversion from the "convert" metadata key, falling back to def when the key is absent.
+func parseConversion(metadata map[string]string, def model.Conversion) (model.Conversion, error) {
+	value, ok := metadata["convert"]
+	if !ok {
+		return def, nil
+	}
+
+	switch conversion := model.Conversion(value); conversion {
+	case model.ConversionAuto, model.ConversionNone, model.ConversionUnderlying, model.ConversionBytes,
+		model.ConversionNumeric, model.ConversionUnix, model.ConversionUnixMilli, model.ConversionRFC3339,
+		model.ConversionStringer:
+		return conversion, nil
 	default:
-		return fmt.Sprintf("%T", expr)
+		return "", fmt.Errorf("invalid conversion %q", value)
 	}
 }
 
-// extractPackage extracts the package name from a type string
-// For example, "*dto.AddressDTO" -> "dto"
-func extractPackage(typeStr string) string {
-	// Remove pointer prefix if present
-	typeStr = strings.TrimPrefix(typeStr, "*")
-	
-	// Split by dot to get package and type
-	parts := strings.Split(typeStr, ".")
-	if len(parts) > 1 {
-		return parts[0]
-	}
-	
-	// No package qualifier
-	return ""
+// parseNullValueStrategy reads the null value strategy from the "nullValueStrategy" metadata key,
+// falling back to def when the key is absent.
+func parseNullValueStrategy(metadata map[string]string, def model.NullValueStrategy) (model.NullValueStrategy, error) {
+	value, ok := metadata["nullValueStrategy"]
+	if !ok {
+		return def, nil
+	}
+
+	switch strategy := model.NullValueStrategy(value); strategy {
+	case model.NullValueOverwrite, model.NullValueSkip:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("invalid nullValueStrategy %q, expected one of: overwrite, skip", value)
+	}
+}
+
+// parseMatchBy reads the struct tags pairing fields from the "matchBy" metadata key, a comma-separated list
+// of "json", "db" or "tag=<key>", or "name" alone for no tags, the default when the key is absent.
+func parseMatchBy(metadata map[string]string) ([]string, error) {
+	value, ok := metadata["matchBy"]
+	if !ok || value == "name" {
+		return nil, nil
+	}
+
+	var tags []string
+	for _, strategy := range strings.Split(value, ",") {
+		switch key, isTag := strings.CutPrefix(strategy, "tag="); {
+		case strategy == "json", strategy == "db":
+			tags = append(tags, strategy)
+		case isTag && key != "" && !strings.ContainsAny(key, ` :"`):
+			tags = append(tags, key)
+		default:
+			return nil, fmt.Errorf("invalid matchBy %q, expected name, or a comma-separated list of json, db or tag=<key>", value)
+		}
+	}
+	return tags, nil
+}
+
+// parseNaming reads the naming strategy from the "naming" metadata key, falling back to def when the key is absent.
+func parseNaming(metadata map[string]string, def model.NamingStrategy) (model.NamingStrategy, error) {
+	value, ok := metadata["naming"]
+	if !ok {
+		return def, nil
+	}
+
+	switch naming := model.NamingStrategy(value); naming {
+	case model.NamingExact, model.NamingCaseInsensitive, model.NamingNormalized:
+		return naming, nil
+	default:
+		return "", fmt.Errorf("invalid naming %q, expected one of: exact, caseInsensitive, normalized", value)
+	}
+}
+
+// parsePrefixes reads the comma-separated field name prefixes from the metadata key.
+func parsePrefixes(metadata map[string]string, key string) ([]string, error) {
+	value, ok := metadata[key]
+	if !ok {
+		return nil, nil
+	}
+
+	prefixes := strings.Split(value, ",")
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			return nil, fmt.Errorf("invalid %s %q, expected comma-separated prefixes", key, value)
+		}
+	}
+	return prefixes, nil
 }
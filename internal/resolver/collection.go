@@ -0,0 +1,88 @@
+package resolver
+
+import (
+	"fmt"
+	"go/types"
+
+	"github.com/nduyhai/mapgen/internal/model"
+)
+
+// collectionConversion returns the function expression mapping the collection src to the collection dst
+// element by element, and whether both types are collections of the same kind.
+// Elements are mapped by a mapper method, or by the conversion selected by name.
+// The mapping is generated once per pair of types, as a helper method of the mapper implementation,
+// which returns an error when mapping an element can fail and takes a context when mapping an element does.
+func (r *Resolver) collectionConversion(pkg *types.Package, mapper *model.MapperDefinition, src, dst types.Type, name model.Conversion) (conversionFunc, bool, error) {
+	collection, ok, err := r.resolveCollection(pkg, mapper, src, dst, name)
+	if !ok || err != nil {
+		return conversionFunc{}, ok, err
+	}
+
+	for _, existing := range mapper.Collections {
+		if existing.SourceType == collection.SourceType && existing.TargetType == collection.TargetType {
+			return conversionFunc{expr: "m." + existing.Name, returnsError: existing.ReturnsError, takesContext: existing.Context,
+				fallible: collection.ElemFuncFallible}, true, nil
+		}
+	}
+
+	collection.Name = fmt.Sprintf("map%s%d", collection.Kind, len(mapper.Collections)+1)
+	collection.ReturnsError = collection.ElemFuncError
+	collection.Context = collection.ElemFuncContext
+	if collection.ReturnsError {
+		// Errors of the elements are wrapped with their index or key
+		r.addImport(mapper, "fmt", "fmt")
+	}
+	if collection.Context {
+		r.addImport(mapper, "context", "context")
+	}
+	mapper.Collections = append(mapper.Collections, collection)
+	return conversionFunc{expr: "m." + collection.Name, returnsError: collection.ReturnsError, takesContext: collection.Context,
+		fallible: collection.ElemFuncFallible}, true, nil
+}
+
+// resolveCollection describes the element by element mapping of the collection src to the collection dst,
+// and reports whether both types are slices, arrays of the same length or maps with the same key type.
+func (r *Resolver) resolveCollection(pkg *types.Package, mapper *model.MapperDefinition, src, dst types.Type, name model.Conversion) (model.CollectionMapping, bool, error) {
+	var (
+		kind             model.CollectionKind
+		srcElem, dstElem types.Type
+	)
+
+	switch s := src.Underlying().(type) {
+	case *types.Slice:
+		d, ok := dst.Underlying().(*types.Slice)
+		if !ok {
+			return model.CollectionMapping{}, false, nil
+		}
+		kind, srcElem, dstElem = model.CollectionSlice, s.Elem(), d.Elem()
+	case *types.Array:
+		d, ok := dst.Underlying().(*types.Array)
+		if !ok || d.Len() != s.Len() {
+			return model.CollectionMapping{}, false, nil
+		}
+		kind, srcElem, dstElem = model.CollectionArray, s.Elem(), d.Elem()
+	case *types.Map:
+		d, ok := dst.Underlying().(*types.Map)
+		if !ok || !types.AssignableTo(s.Key(), d.Key()) {
+			return model.CollectionMapping{}, false, nil
+		}
+		kind, srcElem, dstElem = model.CollectionMap, s.Elem(), d.Elem()
+	default:
+		return model.CollectionMapping{}, false, nil
+	}
+
+	elemFunc, err := r.conversion(pkg, mapper, srcElem, dstElem, name)
+	if err != nil {
+		return model.CollectionMapping{}, true, fmt.Errorf("elements: %w", err)
+	}
+
+	return model.CollectionMapping{
+		Kind:             kind,
+		SourceType:       r.qualifiedType(pkg, mapper, src),
+		TargetType:       r.qualifiedType(pkg, mapper, dst),
+		ElemFunc:         elemFunc.expr,
+		ElemFuncError:    elemFunc.returnsError,
+		ElemFuncContext:  elemFunc.takesContext,
+		ElemFuncFallible: elemFunc.fallible,
+	}, true, nil
+}
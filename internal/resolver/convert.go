@@ -0,0 +1,332 @@
+package resolver
+
+import (
+	"fmt"
+	"go/types"
+
+	"github.com/nduyhai/mapgen/internal/model"
+)
+
+// convertPackage is the import path of the package providing the built-in conversions.
+const convertPackage = "github.com/nduyhai/mapgen/convert"
+
+// defaultConversions are the conversions tried, in order, by model.ConversionAuto.
+// Unix milliseconds are only used when selected, as time.Time and int64 default to Unix seconds.
+var defaultConversions = []model.Conversion{
+	model.ConversionUnderlying,
+	model.ConversionBytes,
+	model.ConversionNumeric,
+	model.ConversionUnix,
+	model.ConversionRFC3339,
+	model.ConversionStringer,
+}
+
+// conversionFunc is a function converting a value in the generated code.
+type conversionFunc struct {
+	// expr is the function expression, e.g. "convert.TimeToUnix" or "m.ToDTO", empty when no conversion is needed
+	expr string
+	// returnsError reports whether the function returns an error after the converted value
+	returnsError bool
+	// takesContext reports whether the function takes a context.Context before the value
+	takesContext bool
+	// fallible reports whether the function is, or maps elements with, a built-in conversion that can fail
+	// and was tried by auto rather than selected, e.g. convert.Numeric narrowing an int64 to an int32
+	fallible bool
+}
+
+// conversion returns the function converting a value of type src to type dst,
+// with an empty expression when src is assignable to dst.
+// Methods of the mapper and of the mappers it uses are preferred over the built-in conversions,
+// and collections are converted element by element.
+// The conversion is selected by name, falling back to the mapper's default when name is empty.
+// It fails when no selected conversion fits the types.
+func (r *Resolver) conversion(pkg *types.Package, mapper *model.MapperDefinition, src, dst types.Type, name model.Conversion) (conversionFunc, error) {
+	if types.AssignableTo(src, dst) {
+		return conversionFunc{}, nil
+	}
+
+	if method := lookupMapperMethod(pkg, mapper, src, dst); method.expr != "" {
+		return method, nil
+	}
+
+	if fn, ok, err := r.collectionConversion(pkg, mapper, src, dst, name); ok {
+		return fn, err
+	}
+
+	// selected is the conversion selected by name or on the mapper rather than tried by auto
+	var (
+		candidates []model.Conversion
+		selected   model.Conversion
+	)
+	switch {
+	case name == "" && mapper.Convert != model.ConversionNone && mapper.Convert != model.ConversionAuto:
+		// A conversion selected on the mapper is preferred over the default ones
+		candidates = append([]model.Conversion{mapper.Convert}, defaultConversions...)
+		selected = mapper.Convert
+	case name == "":
+		return r.conversion(pkg, mapper, src, dst, mapper.Convert)
+	case name == model.ConversionAuto:
+		candidates = defaultConversions
+	case name != model.ConversionNone:
+		candidates = []model.Conversion{name}
+		selected = name
+	}
+
+	for _, candidate := range candidates {
+		if expr, returnsError, ok := r.applyConversion(pkg, mapper, src, dst, candidate, candidate == selected); ok {
+			return conversionFunc{expr: expr, returnsError: returnsError, fallible: returnsError && candidate != selected}, nil
+		}
+	}
+
+	if len(candidates) != 1 {
+		return conversionFunc{}, fmt.Errorf("cannot assign %s to %s", typeString(pkg, src), typeString(pkg, dst))
+	}
+	return conversionFunc{}, fmt.Errorf("conversion %s cannot convert %s to %s", name, typeString(pkg, src), typeString(pkg, dst))
+}
+
+// applyConversion returns the function expression of the conversion from src to dst,
+// whether the function also returns an error, and whether the conversion fits the types.
+// Selected reports whether the conversion was selected rather than tried by auto: two named types,
+// e.g. UserID and OrderID, are only converted by the underlying and numeric conversions when it is.
+func (r *Resolver) applyConversion(pkg *types.Package, mapper *model.MapperDefinition, src, dst types.Type, conversion model.Conversion, selected bool) (string, bool, bool) {
+	switch conversion {
+	case model.ConversionUnderlying:
+		if isPointer(src) || isPointer(dst) || !types.Identical(src.Underlying(), dst.Underlying()) || (!selected && namedPair(src, dst)) {
+			return "", false, false
+		}
+		return r.qualifiedType(pkg, mapper, dst), false, true
+
+	case model.ConversionBytes:
+		if (isString(src) && isBytes(dst)) || (isBytes(src) && isString(dst)) {
+			return r.qualifiedType(pkg, mapper, dst), false, true
+		}
+		return "", false, false
+
+	case model.ConversionNumeric:
+		if !isNumber(src) || !isNumber(dst) || (!selected && namedPair(src, dst)) {
+			return "", false, false
+		}
+		if widens(src, dst) {
+			return r.qualifiedType(pkg, mapper, dst), false, true
+		}
+		// Narrowing fails when the value does not fit the target type
+		return r.convertFunc(mapper, "Numeric") + "[" + r.qualifiedType(pkg, mapper, dst) + "]", true, true
+
+	case model.ConversionUnix, model.ConversionUnixMilli:
+		suffix := "Unix"
+		if conversion == model.ConversionUnixMilli {
+			suffix = "UnixMilli"
+		}
+		switch {
+		case isTime(src) && isBasic(dst, types.Int64):
+			return r.convertFunc(mapper, "TimeTo"+suffix), false, true
+		case isBasic(src, types.Int64) && isTime(dst):
+			return r.convertFunc(mapper, suffix+"ToTime"), false, true
+		}
+		return "", false, false
+
+	case model.ConversionRFC3339:
+		switch {
+		case isTime(src) && isBasic(dst, types.String):
+			return r.convertFunc(mapper, "TimeToRFC3339"), false, true
+		case isBasic(src, types.String) && isTime(dst):
+			return r.convertFunc(mapper, "RFC3339ToTime"), true, true
+		}
+		return "", false, false
+
+	case model.ConversionStringer:
+		if isBasic(dst, types.String) && types.Implements(src, stringer) {
+			return r.convertFunc(mapper, "String"), false, true
+		}
+		return "", false, false
+	}
+
+	return "", false, false
+}
+
+// convertFunc returns the qualified name of a function of the convert package
+// and adds the package to the imports of the mapper.
+func (r *Resolver) convertFunc(mapper *model.MapperDefinition, name string) string {
+	return r.addImport(mapper, convertPackage, "convert") + "." + name
+}
+
+// qualifiedType formats t as it is written in the generated code, which is also
+// the conversion expression to t as pointer types are never converted,
+// adding the packages it refers to to the imports of the mapper.
+func (r *Resolver) qualifiedType(pkg *types.Package, mapper *model.MapperDefinition, t types.Type) string {
+	return types.TypeString(t, func(other *types.Package) string {
+		if other == pkg {
+			return ""
+		}
+		return r.addImport(mapper, other.Path(), other.Name())
+	})
+}
+
+// builtinImports are the packages the generated code refers to by their own name, by name.
+var builtinImports = map[string]string{"fmt": "fmt", "context": "context", "convert": convertPackage}
+
+// addImport adds the package with the import path and name to the imports of the mapper, unless it is
+// already imported, and returns the name the generated code refers to it by.
+// Packages are imported under their own name, unless it is taken by another import, by the packages
+// of the generated code itself, or by an identifier of the mapper's generated code, e.g. a parameter
+// named like the package: the package is then aliased with a number, e.g. "model2".
+func (r *Resolver) addImport(mapper *model.MapperDefinition, path, name string) string {
+	taken := make(map[string]bool)
+	for _, imp := range mapper.Imports {
+		if imp.Path == path {
+			return imp.Name
+		}
+		taken[imp.Name] = true
+	}
+
+	available := func(alias string) bool {
+		if builtin, ok := builtinImports[alias]; ok {
+			return builtin == path
+		}
+		return !taken[alias] && !r.reserved[alias]
+	}
+	alias := name
+	for n := 2; !available(alias); n++ {
+		alias = fmt.Sprintf("%s%d", name, n)
+	}
+
+	mapper.Imports = append(mapper.Imports, model.Import{Path: path, Name: alias, Alias: alias != name})
+	return alias
+}
+
+// reservedIdents returns the identifiers of the generated code of the mapper that imported packages must
+// not be named: the variables of the generated methods, their parameters, the implementation and its
+// constructor, and the package-level and predeclared identifiers they may refer to.
+func reservedIdents(pkg *types.Package, mapper *model.MapperDefinition, iface *types.Interface) map[string]bool {
+	reserved := make(map[string]bool)
+	for _, name := range []string{"m", "in", "out", "ctx", "err", "v", "i", "k", "elem", mapper.ImplName, mapper.Constructor} {
+		reserved[name] = true
+	}
+	for _, name := range pkg.Scope().Names() {
+		reserved[name] = true
+	}
+	for _, name := range types.Universe.Names() {
+		reserved[name] = true
+	}
+	for i := 0; i < iface.NumMethods(); i++ {
+		params := iface.Method(i).Type().(*types.Signature).Params()
+		for j := 0; j < params.Len(); j++ {
+			reserved[params.At(j).Name()] = true
+		}
+	}
+	return reserved
+}
+
+// stringer is the fmt.Stringer interface.
+var stringer = types.NewInterfaceType([]*types.Func{
+	types.NewFunc(0, nil, "String", types.NewSignatureType(nil, nil, nil, nil,
+		types.NewTuple(types.NewVar(0, nil, "", types.Typ[types.String])), false)),
+}, nil).Complete()
+
+// namedPair reports whether src and dst are both named types, e.g. UserID and OrderID, rather than
+// one of them being its own underlying type, e.g. int64.
+func namedPair(src, dst types.Type) bool {
+	return !types.Identical(src, src.Underlying()) && !types.Identical(dst, dst.Underlying())
+}
+
+// isTime reports whether t is time.Time.
+func isTime(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time"
+}
+
+// isContext reports whether t is context.Context.
+func isContext(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "context" && obj.Name() == "Context"
+}
+
+// isBasic reports whether t is exactly the basic type of the given kind.
+func isBasic(t types.Type, kind types.BasicKind) bool {
+	basic, ok := t.(*types.Basic)
+	return ok && basic.Kind() == kind
+}
+
+// isPointer reports whether the underlying type of t is a pointer.
+func isPointer(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Pointer)
+	return ok
+}
+
+// isString reports whether the underlying type of t is string.
+func isString(t types.Type) bool {
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
+
+// isBytes reports whether the underlying type of t is []byte.
+func isBytes(t types.Type) bool {
+	slice, ok := t.Underlying().(*types.Slice)
+	if !ok {
+		return false
+	}
+	basic, ok := slice.Elem().Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.Byte
+}
+
+// isNumber reports whether the underlying type of t is an integer or floating-point type.
+func isNumber(t types.Type) bool {
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Info()&(types.IsInteger|types.IsFloat) != 0
+}
+
+// widens reports whether every value of the numeric type src can be represented by dst.
+// As the size of int and uint depends on the platform, they count as 64 bits when
+// converted from and as 32 bits when converted to.
+func widens(src, dst types.Type) bool {
+	s := src.Underlying().(*types.Basic)
+	d := dst.Underlying().(*types.Basic)
+
+	srcBits, dstBits := bitSize(s, 64), bitSize(d, 32)
+	srcUnsigned := s.Info()&types.IsUnsigned != 0
+	dstUnsigned := d.Info()&types.IsUnsigned != 0
+
+	switch {
+	case s.Info()&types.IsFloat != 0:
+		return d.Info()&types.IsFloat != 0 && dstBits >= srcBits
+	case d.Info()&types.IsFloat != 0:
+		// Floats represent integers exactly up to their mantissa size
+		mantissa := 24
+		if dstBits == 64 {
+			mantissa = 53
+		}
+		return srcBits < mantissa
+	case srcUnsigned == dstUnsigned:
+		return dstBits >= srcBits
+	case srcUnsigned:
+		return dstBits > srcBits
+	default:
+		// Negative values cannot be represented by unsigned types
+		return false
+	}
+}
+
+// bitSize returns the size in bits of the basic numeric type,
+// using platformBits for int, uint and uintptr.
+func bitSize(t *types.Basic, platformBits int) int {
+	switch t.Kind() {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32, types.Float32:
+		return 32
+	case types.Int64, types.Uint64, types.Float64:
+		return 64
+	default:
+		return platformBits
+	}
+}
@@ -0,0 +1,121 @@
+package resolver
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nduyhai/mapgen/internal/model"
+)
+
+// resolveErrors prepares the rules of the method whose conversion can fail.
+// Methods returning an error assign the converted value to a variable and return the error,
+// wrapped with the source field it was read from, e.g. "mapping User.CreatedAt: ...".
+// Other methods panic on such errors through convert.Must.
+func (r *Resolver) resolveErrors(pkg *types.Package, mapper *model.MapperDefinition, method *model.MapperMethod, sources []source) {
+	used := make(map[string]bool)
+	for _, s := range sources {
+		used[s.name] = true
+	}
+	for _, imp := range mapper.Imports {
+		used[imp.Name] = true
+	}
+
+	for i := range method.Mappings {
+		rule := &method.Mappings[i]
+		if rule.Ignore || (!rule.CustomFuncError && !rule.ConversionError) {
+			continue
+		}
+
+		r.addErrorImport(mapper, method)
+		if !method.ReturnsError {
+			continue
+		}
+
+		rule.Var = valueVar(pkg, rule.TargetField, used)
+		rule.ErrorContext = errorContext(sources, rule.SourceExpr)
+	}
+}
+
+// addErrorImport adds the package handling the errors of conversions to the imports of the mapper:
+// fmt to wrap them in methods returning an error, or the convert package to panic otherwise.
+func (r *Resolver) addErrorImport(mapper *model.MapperDefinition, method *model.MapperMethod) {
+	if method.ReturnsError {
+		r.addImport(mapper, "fmt", "fmt")
+	} else {
+		r.addImport(mapper, convertPackage, "convert")
+	}
+}
+
+// reservedVars are the identifiers of the generated methods that value variables must not shadow.
+var reservedVars = map[string]bool{"m": true, "in": true, "out": true, "ctx": true, "err": true, "v": true, "fmt": true, "convert": true}
+
+// valueVar returns a variable name for the value converted for the target path, e.g. "createdAt"
+// for "CreatedAt", that is not used yet and does not shadow the identifiers of the generated code.
+// Used holds the names of the sources and of the imported packages, and the names returned so far.
+func valueVar(pkg *types.Package, targetPath string, used map[string]bool) string {
+	name := lowerInitial(strings.ReplaceAll(targetPath, ".", ""))
+
+	shadows := func(name string) bool {
+		return used[name] || reservedVars[name] || token.IsKeyword(name) ||
+			types.Universe.Lookup(name) != nil || pkg.Scope().Lookup(name) != nil
+	}
+	if shadows(name) {
+		name += "Value"
+	}
+	base := name
+	for n := 2; shadows(name); n++ {
+		name = fmt.Sprintf("%s%d", base, n)
+	}
+
+	used[name] = true
+	return name
+}
+
+// lowerInitial lower-cases the leading upper-case letters of name, the last one excepted when it starts
+// the next word, e.g. "createdAt" for "CreatedAt", "id" for "ID" and "urlPath" for "URLPath".
+func lowerInitial(name string) string {
+	upper := 0
+	for upper < len(name) {
+		r, size := utf8.DecodeRuneInString(name[upper:])
+		if !unicode.IsUpper(r) {
+			break
+		}
+		upper += size
+	}
+
+	if next, _ := utf8.DecodeRuneInString(name[upper:]); unicode.IsLower(next) {
+		if _, size := utf8.DecodeLastRuneInString(name[:upper]); size < upper {
+			upper -= size
+		}
+	}
+	return strings.ToLower(name[:upper]) + name[upper:]
+}
+
+// errorContext describes the source field read by expr for error messages, naming its source
+// by type rather than by parameter, e.g. "User.CreatedAt" for "in.CreatedAt".
+func errorContext(sources []source, expr string) string {
+	name, path, _ := strings.Cut(expr, ".")
+	for _, s := range sources {
+		if s.name != name {
+			continue
+		}
+
+		t := s.typ
+		if ptr, ok := t.(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		typeName := types.TypeString(t, func(*types.Package) string { return "" })
+		if named, ok := t.(*types.Named); ok {
+			typeName = named.Obj().Name()
+		}
+		if path == "" {
+			return typeName
+		}
+		return typeName + "." + path
+	}
+	return expr
+}
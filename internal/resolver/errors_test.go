@@ -0,0 +1,39 @@
+package resolver
+
+import (
+	"go/types"
+	"testing"
+)
+
+func TestValueVar(t *testing.T) {
+	tests := []struct {
+		name       string
+		targetPath string
+		used       map[string]bool
+		want       string
+	}{
+		{name: "field", targetPath: "CreatedAt", want: "createdAt"},
+		{name: "initialism", targetPath: "ID", want: "id"},
+		{name: "leading initialism", targetPath: "URLPath", want: "urlPath"},
+		{name: "single letter", targetPath: "X", want: "x"},
+		{name: "lower-case field", targetPath: "name", want: "name"},
+		{name: "non-ASCII", targetPath: "Über", want: "über"},
+		{name: "path", targetPath: "Address.City", want: "addressCity"},
+		{name: "reserved", targetPath: "Out", want: "outValue"},
+		{name: "keyword", targetPath: "Type", want: "typeValue"},
+		{name: "universe", targetPath: "Len", want: "lenValue"},
+		{name: "used", targetPath: "ID", used: map[string]bool{"id": true, "idValue": true}, want: "idValue2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used := tt.used
+			if used == nil {
+				used = make(map[string]bool)
+			}
+			pkg := types.NewPackage("example.com/mapper", "mapper")
+			if got := valueVar(pkg, tt.targetPath, used); got != tt.want {
+				t.Errorf("valueVar(%q) = %q, want %q", tt.targetPath, got, tt.want)
+			}
+		})
+	}
+}
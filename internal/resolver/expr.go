@@ -0,0 +1,255 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"math"
+	"strings"
+
+	"github.com/nduyhai/mapgen/internal/model"
+)
+
+// checkValue type-checks the Go expression of a constant: or default: value, such as `"v1"`, `42`
+// or `StatusActive`, and returns it as it is written in the generated code.
+// The expression is evaluated in the scope of the file declaring the mapper at pos, so it may refer
+// to the constants of the mapper's package and of the packages imported by the file.
+// It must be a constant, or nil, assignable to the target type.
+func (r *Resolver) checkValue(pkg *types.Package, mapper *model.MapperDefinition, pos token.Pos, value string, target types.Type) (string, error) {
+	expr, err := parser.ParseExpr(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid value %s: %v", value, err)
+	}
+
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	if err := types.CheckExpr(r.fset, pkg, pos, expr, info); err != nil {
+		// The expression is parsed on its own, its positions are meaningless in the file set
+		if typeErr, ok := err.(types.Error); ok {
+			err = errors.New(typeErr.Msg)
+		}
+		return "", fmt.Errorf("invalid value %s: %v", value, err)
+	}
+
+	tv := info.Types[expr]
+	if tv.Value == nil && !tv.IsNil() {
+		return "", fmt.Errorf("value %s is not a constant", value)
+	}
+	if !assignableValue(tv, target) {
+		return "", fmt.Errorf("value %s of type %s cannot be assigned to %s", value, typeString(pkg, tv.Type), typeString(pkg, target))
+	}
+
+	r.importPackages(mapper, expr, info)
+	return formatExpr(expr)
+}
+
+// checkExpr type-checks the Go expression of an expr: value over the sources of the method,
+// such as `in.FirstName + " " + in.LastName`, and returns it as it is written in the generated code,
+// along with the sources and source fields it reads, e.g. ["in.FirstName", "in.LastName"].
+// The expression is checked as the body of a function taking the sources, and the context of the method
+// if it has one, declared in the file of the mapper at pos. Its value must be assignable to the target type.
+func (r *Resolver) checkExpr(pkg *types.Package, mapper *model.MapperDefinition, method *model.MapperMethod, sources []source, pos token.Pos, value string, target types.Type) (string, []string, error) {
+	expr, err := parser.ParseExpr(value)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid expression %s: %v", value, err)
+	}
+
+	// The parameters are declared with the names of the packages as imported by the file
+	imports := fileImports(pkg, pos)
+	qualifier := func(other *types.Package) string {
+		if other == pkg {
+			return ""
+		}
+		if name, ok := imports[other.Path()]; ok {
+			return name
+		}
+		return other.Name()
+	}
+
+	var params []string
+	if method.Context {
+		context, ok := imports["context"]
+		if !ok {
+			context = "context"
+		}
+		params = append(params, "ctx "+context+".Context")
+	}
+	for _, s := range sources {
+		params = append(params, s.name+" "+types.TypeString(s.typ, qualifier))
+	}
+
+	wrapper, err := parser.ParseExpr(fmt.Sprintf("func(%s) { _ = (%s) }", strings.Join(params, ", "), value))
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid expression %s: %v", value, err)
+	}
+	body := wrapper.(*ast.FuncLit).Body.List[0].(*ast.AssignStmt)
+	expr = body.Rhs[0].(*ast.ParenExpr).X
+
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	if err := types.CheckExpr(r.fset, pkg, pos, wrapper, info); err != nil {
+		if typeErr, ok := err.(types.Error); ok {
+			err = errors.New(typeErr.Msg)
+		}
+		return "", nil, fmt.Errorf("invalid expression %s: %v", value, err)
+	}
+
+	tv := info.Types[expr]
+	if !assignableValue(tv, target) {
+		return "", nil, fmt.Errorf("expression %s of type %s cannot be assigned to %s", value, typeString(pkg, tv.Type), typeString(pkg, target))
+	}
+
+	r.importPackages(mapper, expr, info)
+
+	formatted, err := formatExpr(expr)
+	if err != nil {
+		return "", nil, err
+	}
+	return formatted, sourceReads(expr, info, sources), nil
+}
+
+// sourceReads returns the sources and source fields read by expr, e.g. "in" or "in.FirstName".
+func sourceReads(expr ast.Expr, info *types.Info, sources []source) []string {
+	isSource := func(ident *ast.Ident) bool {
+		if _, ok := info.Uses[ident].(*types.Var); !ok {
+			return false
+		}
+		for _, s := range sources {
+			if s.name == ident.Name {
+				return true
+			}
+		}
+		return false
+	}
+
+	var reads []string
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.SelectorExpr:
+			if ident, ok := n.X.(*ast.Ident); ok && isSource(ident) {
+				reads = append(reads, ident.Name+"."+n.Sel.Name)
+				return false
+			}
+		case *ast.Ident:
+			if isSource(n) {
+				reads = append(reads, n.Name)
+			}
+		}
+		return true
+	})
+	return reads
+}
+
+// fileImports returns the names under which the file containing pos imports packages, by import path.
+func fileImports(pkg *types.Package, pos token.Pos) map[string]string {
+	imports := make(map[string]string)
+	scope := pkg.Scope().Innermost(pos)
+	if scope == nil {
+		return imports
+	}
+	for _, name := range scope.Names() {
+		if pkgName, ok := scope.Lookup(name).(*types.PkgName); ok {
+			imports[pkgName.Imported().Path()] = name
+		}
+	}
+	return imports
+}
+
+// importPackages adds the packages expr refers to to the imports of the mapper, and renames their
+// qualifiers to the names the generated code imports them by, e.g. "dto.StatusActive" to "model2.StatusActive".
+func (r *Resolver) importPackages(mapper *model.MapperDefinition, expr ast.Expr, info *types.Info) {
+	ast.Inspect(expr, func(node ast.Node) bool {
+		ident, ok := node.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if pkgName, ok := info.Uses[ident].(*types.PkgName); ok {
+			imported := pkgName.Imported()
+			ident.Name = r.addImport(mapper, imported.Path(), imported.Name())
+		}
+		return true
+	})
+}
+
+// formatExpr formats expr as Go source.
+func formatExpr(expr ast.Expr) (string, error) {
+	var b strings.Builder
+	if err := format.Node(&b, token.NewFileSet(), expr); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// assignableValue reports whether the value tv can be assigned to t.
+// Unlike types.AssignableTo, untyped constants must also be representable by t, e.g. 300 cannot be assigned to int8.
+func assignableValue(tv types.TypeAndValue, t types.Type) bool {
+	if tv.IsNil() {
+		return isNilable(t)
+	}
+	if tv.Value == nil {
+		// Untyped values that are not constants, such as comparisons, are left to go/types
+		return types.AssignableTo(tv.Type, t)
+	}
+
+	basic, ok := tv.Type.(*types.Basic)
+	if !ok || basic.Info()&types.IsUntyped == 0 {
+		return types.AssignableTo(tv.Type, t)
+	}
+	if types.IsInterface(t) {
+		return types.AssignableTo(types.Default(tv.Type), t)
+	}
+
+	target, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return false
+	}
+	value := tv.Value
+	switch info := target.Info(); {
+	case info&types.IsBoolean != 0:
+		return value.Kind() == constant.Bool
+	case info&types.IsString != 0:
+		return value.Kind() == constant.String
+	case info&types.IsInteger != 0:
+		return representableInt(constant.ToInt(value), target)
+	case info&types.IsFloat != 0:
+		value = constant.ToFloat(value)
+		if value.Kind() != constant.Float {
+			return false
+		}
+		if target.Kind() == types.Float32 {
+			f, _ := constant.Float32Val(value)
+			return !math.IsInf(float64(f), 0)
+		}
+		f, _ := constant.Float64Val(value)
+		return !math.IsInf(f, 0)
+	case info&types.IsComplex != 0:
+		return constant.ToComplex(value).Kind() == constant.Complex
+	}
+	return false
+}
+
+// representableInt reports whether the integer constant value fits the integer type t.
+func representableInt(value constant.Value, t *types.Basic) bool {
+	if value.Kind() != constant.Int {
+		return false
+	}
+
+	bits := uint(bitSize(t, 64))
+	one := constant.MakeInt64(1)
+	if t.Info()&types.IsUnsigned != 0 {
+		max := constant.Shift(one, token.SHL, bits)
+		return constant.Sign(value) >= 0 && constant.Compare(value, token.LSS, max)
+	}
+	max := constant.Shift(one, token.SHL, bits-1)
+	min := constant.UnaryOp(token.SUB, max, 0)
+	return constant.Compare(value, token.GEQ, min) && constant.Compare(value, token.LSS, max)
+}
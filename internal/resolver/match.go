@@ -0,0 +1,218 @@
+package resolver
+
+import (
+	"go/types"
+	"reflect"
+	"strings"
+
+	"github.com/nduyhai/mapgen/internal/model"
+)
+
+// matchFields appends a mapping rule for every target field that has no explicit rule
+// and whose key matches a field of a source that is assignable, or
+// convertible by a built-in conversion, to it.
+// Fields are keyed by name, or by the value of the struct tags selected by the mapper's matchBy option,
+// compared according to the mapper's naming strategy once the mapper's prefixes are stripped.
+// Ignored source fields, e.g. by a `mapgen:"-"` tag, are not matched.
+// It fails when the field matches several source fields, e.g. both PbName and Name when stripping "Pb",
+// rather than picking one arbitrarily, even when only one of them can be converted to it, and when a method without an error result would need a conversion
+// that can fail, e.g. parsing an RFC 3339 string, which must then be selected explicitly.
+func (r *Resolver) matchFields(pkg *types.Package, mapper *model.MapperDefinition, method *model.MapperMethod, sources []source, targetType types.Type) error {
+	target := structOf(targetType)
+	if target == nil {
+		return nil
+	}
+
+	explicit := make(map[string]bool)
+	ignored := make(map[string]bool)
+	for _, rule := range method.Mappings {
+		// A nested path such as "Address.City" configures the field at its root
+		root, _, _ := strings.Cut(rule.TargetField, ".")
+		explicit[root] = true
+		if rule.Ignore {
+			ignored[rule.SourceExpr] = true
+		}
+	}
+
+	sourceKey := func(name, tag string) string {
+		return namingKey(matchKey(name, tag, mapper.MatchTags), mapper.Naming, mapper.StripSourcePrefixes)
+	}
+	candidates := make([]map[string][]*types.Var, len(sources))
+	for i, s := range sources {
+		candidates[i] = fieldsByKey(pkg, s.typ, sourceKey)
+	}
+
+	for i := 0; i < target.NumFields(); i++ {
+		targetField := target.Field(i)
+		if explicit[targetField.Name()] || !accessible(pkg, targetField) {
+			continue
+		}
+		key := namingKey(matchKey(targetField.Name(), target.Tag(i), mapper.MatchTags), mapper.Naming, mapper.StripTargetPrefixes)
+		if key == "" {
+			continue
+		}
+
+		// Several source fields with the key are ambiguous, whichever of them can be converted to the target field
+		var found []string
+		for j, s := range sources {
+			for _, sourceField := range candidates[j][key] {
+				if ignored[s.name+"."+sourceField.Name()] {
+					continue
+				}
+				if len(sources) > 1 {
+					found = append(found, s.name+"."+sourceField.Name())
+				} else {
+					found = append(found, sourceField.Name())
+				}
+			}
+		}
+		if len(found) > 1 {
+			return r.errorf(method.Pos, "%s.%s: target field %s matches source fields %s, add a mapping to select one",
+				mapper.Name, method.Name, targetField.Name(), strings.Join(found, ", "))
+		}
+
+		var matches []model.FieldMappingRule
+		for j, s := range sources {
+			for _, sourceField := range candidates[j][key] {
+				if ignored[s.name+"."+sourceField.Name()] {
+					continue
+				}
+
+				conversion, err := r.conversion(pkg, mapper, sourceField.Type(), targetField.Type(), "")
+				if err != nil {
+					continue
+				}
+				if err := checkContext(method, conversion); err != nil {
+					return r.errorf(method.Pos, "%s.%s: target field %s: %v", mapper.Name, method.Name, targetField.Name(), err)
+				}
+				if conversion.fallible && !method.ReturnsError {
+					return r.errorf(method.Pos, "%s.%s: target field %s: converting %s.%s to %s can fail, "+
+						"select the conversion with a mapping's convert: option or return an error from the method",
+						mapper.Name, method.Name, targetField.Name(), s.name, sourceField.Name(), typeString(pkg, targetField.Type()))
+				}
+
+				rule := model.FieldMappingRule{
+					SourceField:     sourceField.Name(),
+					SourceExpr:      s.name + "." + sourceField.Name(),
+					TargetField:     targetField.Name(),
+					Conversion:      conversion.expr,
+					ConversionError: conversion.returnsError,
+					Context:         conversion.takesContext,
+				}
+				if len(sources) > 1 {
+					rule.SourceField = rule.SourceExpr
+					if isNilable(s.typ) {
+						rule.NilChecks = []string{s.name}
+					}
+				}
+				matches = append(matches, rule)
+			}
+		}
+
+		method.Mappings = append(method.Mappings, matches...)
+	}
+	return nil
+}
+
+// fieldsByKey returns the fields of the struct type t that code generated in pkg can read, by the key
+// returned by key for their name and tag. Fields promoted from embedded structs are included,
+// following the selector rules of Go.
+func fieldsByKey(pkg *types.Package, t types.Type, key func(name, tag string) string) map[string][]*types.Var {
+	var (
+		names     []string
+		fieldTags = make(map[*types.Var]string)
+		seen      = make(map[*types.Struct]bool)
+	)
+	var collect func(t types.Type)
+	collect = func(t types.Type) {
+		s := structOf(t)
+		if s == nil || seen[s] {
+			return
+		}
+		seen[s] = true
+		for i := 0; i < s.NumFields(); i++ {
+			field := s.Field(i)
+			names = append(names, field.Name())
+			fieldTags[field] = s.Tag(i)
+			if field.Embedded() {
+				collect(field.Type())
+			}
+		}
+	}
+	collect(t)
+
+	fields := make(map[string][]*types.Var)
+	found := make(map[*types.Var]bool)
+	for _, name := range names {
+		// Shadowed and ambiguous fields are left out by the lookup
+		field := lookupField(pkg, t, name)
+		if field == nil || found[field] {
+			continue
+		}
+		found[field] = true
+		if k := key(field.Name(), fieldTags[field]); k != "" {
+			fields[k] = append(fields[k], field)
+		}
+	}
+	return fields
+}
+
+// matchKey returns the key a field is matched by: the name held by the first of the tags the field has,
+// e.g. "user_name" for `db:"user_name"`, or the name of the field when it has none.
+// It returns an empty key for a field excluded by a tag, e.g. `json:"-"`.
+func matchKey(name, tag string, tags []string) string {
+	for _, key := range tags {
+		value, ok := reflect.StructTag(tag).Lookup(key)
+		if !ok {
+			continue
+		}
+		tagName := tagFieldName(key, value)
+		if tagName == "-" {
+			return ""
+		}
+		if tagName != "" {
+			return tagName
+		}
+	}
+	return name
+}
+
+// tagFieldName returns the field name held by the value of the struct tag key. It is the first
+// comma-separated element for most tags, e.g. `json:"user_name,omitempty"`, but the name= element for
+// the tags generated by protoc, e.g. `protobuf:"bytes,1,opt,name=user_name,proto3"`.
+func tagFieldName(key, value string) string {
+	if key == "protobuf" {
+		for _, element := range strings.Split(value, ",") {
+			if name, ok := strings.CutPrefix(element, "name="); ok {
+				return name
+			}
+		}
+		return ""
+	}
+	name, _, _ := strings.Cut(value, ",")
+	return name
+}
+
+// namingKey returns the key name is compared by according to the naming strategy, once the first of the
+// prefixes it starts with is stripped. Prefixes are compared as the names are, e.g. "pb" is stripped from
+// "PbName" with NamingCaseInsensitive. A name made of a prefix only keeps it, and an empty name stays empty.
+func namingKey(name string, naming model.NamingStrategy, prefixes []string) string {
+	normalize := func(s string) string {
+		switch naming {
+		case model.NamingCaseInsensitive:
+			return strings.ToLower(s)
+		case model.NamingNormalized:
+			return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(s))
+		default:
+			return s
+		}
+	}
+
+	key := normalize(name)
+	for _, prefix := range prefixes {
+		if stripped, ok := strings.CutPrefix(key, normalize(prefix)); ok && stripped != "" {
+			return stripped
+		}
+	}
+	return key
+}
@@ -0,0 +1,71 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/nduyhai/mapgen/internal/model"
+)
+
+func TestMatchKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		tag   string
+		tags  []string
+		want  string
+	}{
+		{name: "no tags", field: "UserName", tag: `json:"user_name"`, want: "UserName"},
+		{name: "tag name", field: "UserName", tag: `db:"user_name"`, tags: []string{"db"}, want: "user_name"},
+		{name: "tag options", field: "UserName", tag: `json:"user_name,omitempty"`, tags: []string{"json"}, want: "user_name"},
+		{name: "empty tag name", field: "UserName", tag: `json:",omitempty"`, tags: []string{"json"}, want: "UserName"},
+		{name: "missing tag", field: "UserName", tag: `db:"user_name"`, tags: []string{"json"}, want: "UserName"},
+		{name: "excluded", field: "UserName", tag: `json:"-"`, tags: []string{"json"}, want: ""},
+		{name: "first listed tag", field: "UserName", tag: `json:"name" db:"user_name"`, tags: []string{"db", "json"}, want: "user_name"},
+		{name: "next listed tag", field: "UserName", tag: `json:"name"`, tags: []string{"db", "json"}, want: "name"},
+		{
+			name:  "protobuf name",
+			field: "UserName",
+			tag:   `protobuf:"bytes,1,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`,
+			tags:  []string{"protobuf"},
+			want:  "user_name",
+		},
+		{name: "protobuf without name", field: "UserName", tag: `protobuf:"bytes,1,opt"`, tags: []string{"protobuf"}, want: "UserName"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchKey(tt.field, tt.tag, tt.tags); got != tt.want {
+				t.Errorf("matchKey(%q, %q, %q) = %q, want %q", tt.field, tt.tag, tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamingKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    string
+		naming   model.NamingStrategy
+		prefixes []string
+		want     string
+	}{
+		{name: "exact", field: "UserName", naming: model.NamingExact, want: "UserName"},
+		{name: "case insensitive", field: "UserName", naming: model.NamingCaseInsensitive, want: "username"},
+		{name: "normalized underscores", field: "user_name", naming: model.NamingNormalized, want: "username"},
+		{name: "normalized dashes", field: "User-Name", naming: model.NamingNormalized, want: "username"},
+		{name: "normalized initialism", field: "UserID", naming: model.NamingNormalized, want: "userid"},
+		{name: "prefix", field: "PbName", naming: model.NamingExact, prefixes: []string{"Pb"}, want: "Name"},
+		{name: "prefix case", field: "pbName", naming: model.NamingExact, prefixes: []string{"Pb"}, want: "pbName"},
+		{name: "prefix compared as names", field: "pbName", naming: model.NamingCaseInsensitive, prefixes: []string{"Pb"}, want: "name"},
+		{name: "normalized prefix", field: "pb_name", naming: model.NamingNormalized, prefixes: []string{"Pb_"}, want: "name"},
+		{name: "first matching prefix", field: "PbXName", naming: model.NamingExact, prefixes: []string{"Db", "Pb", "PbX"}, want: "XName"},
+		{name: "prefix only", field: "Pb", naming: model.NamingExact, prefixes: []string{"Pb"}, want: "Pb"},
+		{name: "empty", field: "", naming: model.NamingNormalized, prefixes: []string{"Pb"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := namingKey(tt.field, tt.naming, tt.prefixes); got != tt.want {
+				t.Errorf("namingKey(%q, %s, %q) = %q, want %q", tt.field, tt.naming, tt.prefixes, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,614 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+	"log"
+	"strings"
+
+	"github.com/nduyhai/mapgen/internal/model"
+)
+
+// Resolver completes mapper definitions using the type information of the
+// package that declares the mapper interface.
+// Target fields that are not covered by an explicit mapping rule are matched
+// to source fields with the same name, or the same tag name with matchBy, so directives
+// are only needed for the exceptions.
+//
+// Every explicit mapping rule, declared by a directive or by a struct tag, is checked against
+// the source and target types, and errors point to the directive or the field that declared the rule.
+type Resolver struct {
+	// fset provides position information for the directives of the rules
+	fset *token.FileSet
+	// reserved are the identifiers of the generated code of the mapper being resolved,
+	// which imported packages are not named after
+	reserved map[string]bool
+}
+
+// NewResolver creates a new Resolver instance.
+// The file set is the one the mapper interfaces were parsed with.
+func NewResolver(fset *token.FileSet) *Resolver {
+	return &Resolver{
+		fset: fset,
+	}
+}
+
+// Resolve adds the implicit same-name field mappings to every method of the mapper.
+// The imports of the generated code are collected from the types it refers to.
+// The mapper interface is looked up by name in the scope of pkg.
+func (r *Resolver) Resolve(pkg *types.Package, mapper *model.MapperDefinition) error {
+	obj := pkg.Scope().Lookup(mapper.Name)
+	if obj == nil {
+		return fmt.Errorf("mapper interface %s not found in package %s", mapper.Name, pkg.Name())
+	}
+
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return r.errorf(obj.Pos(), "mapper must be declared on an interface, %s is %s", mapper.Name, typeString(pkg, obj.Type().Underlying()))
+	}
+	r.reserved = reservedIdents(pkg, mapper, iface)
+
+	if err := r.resolveUses(pkg, mapper, obj.Pos()); err != nil {
+		return err
+	}
+
+	for i := range mapper.Methods {
+		method := &mapper.Methods[i]
+
+		signature := lookupSignature(iface, method.Name)
+		if signature == nil {
+			continue
+		}
+
+		sources, targetType, err := r.resolveSignature(pkg, mapper, method, signature)
+		if err != nil {
+			return err
+		}
+		method.Sources = nil
+		method.SourceNilable = true
+		for _, s := range sources {
+			method.Sources = append(method.Sources, model.SourceParameter{Name: s.name, Type: r.qualifiedType(pkg, mapper, s.typ)})
+			method.SourceNilable = method.SourceNilable && isNilable(s.typ)
+		}
+		method.TargetType = r.qualifiedType(pkg, mapper, targetType)
+
+		if structOf(targetType) == nil {
+			if len(sources) > 1 {
+				return r.errorf(method.Pos, "%s.%s: cannot map several sources to %s", mapper.Name, method.Name, typeString(pkg, targetType))
+			}
+			collection, ok, err := r.resolveCollection(pkg, mapper, sources[0].typ, targetType, "")
+			if !ok {
+				return r.errorf(method.Pos, "%s.%s: cannot map %s to %s", mapper.Name, method.Name, typeString(pkg, sources[0].typ), typeString(pkg, targetType))
+			}
+			if err != nil {
+				return r.errorf(method.Pos, "%s.%s: %v", mapper.Name, method.Name, err)
+			}
+			collection.Name = method.Name
+			collection.ReturnsError = method.ReturnsError
+			collection.Context = method.Context
+			if collection.ElemFuncError {
+				r.addErrorImport(mapper, method)
+			}
+			if collection.ElemFuncFallible && !method.ReturnsError {
+				return r.errorf(method.Pos, "%s.%s: converting the elements of %s to %s can fail, "+
+					"select the conversion with the mapper's convert: option or return an error from the method",
+					mapper.Name, method.Name, typeString(pkg, sources[0].typ), typeString(pkg, targetType))
+			}
+			if collection.ElemFuncContext && !method.Context {
+				return r.errorf(method.Pos, "%s.%s: elements are mapped by %s, which takes a context.Context, add one to the method",
+					mapper.Name, method.Name, collection.ElemFunc)
+			}
+			method.Collection = &collection
+			continue
+		}
+
+		// The target struct is allocated when the method returns a pointer
+		method.TargetValueType = method.TargetType
+		if ptr, ok := targetType.(*types.Pointer); ok {
+			method.TargetPointer = true
+			method.TargetValueType = r.qualifiedType(pkg, mapper, ptr.Elem())
+		} else if _, ok := targetType.Underlying().(*types.Pointer); ok {
+			return r.errorf(method.Pos, "%s.%s: named pointer target type %s is not supported", mapper.Name, method.Name, typeString(pkg, targetType))
+		}
+		switch {
+		case method.Update:
+			method.ZeroResult = ""
+		case method.TargetPointer:
+			method.ZeroResult = "nil"
+		default:
+			method.ZeroResult = method.TargetType + "{}"
+		}
+
+		if err := r.tagRules(pkg, mapper, method, sources, targetType); err != nil {
+			return err
+		}
+		if err := r.checkRules(pkg, mapper, method, sources, targetType); err != nil {
+			return err
+		}
+
+		if err := r.matchFields(pkg, mapper, method, sources, targetType); err != nil {
+			return err
+		}
+		r.resolveNullValues(mapper, method)
+		r.resolveErrors(pkg, mapper, method, sources)
+
+		if err := r.checkUnmappedTargets(pkg, mapper, method, targetType); err != nil {
+			return err
+		}
+		if err := r.checkUnmappedSources(pkg, mapper, method, sources); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveSignature returns the sources and the target type of the mapper method.
+// A method either maps its sources to a new target, e.g. ToDTO(*User) *UserDTO or ToDTO(*User) (*UserDTO, error),
+// or updates the target passed after its sources, optionally returning an error, e.g. MapInto(*User, *UserDTO).
+// Both may take a leading context.Context, e.g. ToDTO(context.Context, *User) *UserDTO.
+func (r *Resolver) resolveSignature(pkg *types.Package, mapper *model.MapperDefinition, method *model.MapperMethod, signature *types.Signature) ([]source, types.Type, error) {
+	params, results := signature.Params(), signature.Results()
+
+	var vars []*types.Var
+	for i := 0; i < params.Len(); i++ {
+		vars = append(vars, params.At(i))
+	}
+
+	// A leading context is passed through to the converters rather than mapped
+	if len(vars) > 0 && isContext(vars[0].Type()) {
+		method.Context = true
+		r.addImport(mapper, "context", "context")
+		vars = vars[1:]
+	}
+
+	var targetType types.Type
+	switch {
+	case signature.Variadic():
+	case len(vars) > 0 && results.Len() == 1 && !types.Identical(results.At(0).Type(), errorType):
+		targetType = results.At(0).Type()
+
+	case len(vars) > 0 && results.Len() == 2 && types.Identical(results.At(1).Type(), errorType):
+		targetType = results.At(0).Type()
+		method.ReturnsError = true
+
+	case len(vars) > 1 && (results.Len() == 0 || (results.Len() == 1 && types.Identical(results.At(0).Type(), errorType))):
+		targetType = vars[len(vars)-1].Type()
+		if _, ok := targetType.(*types.Pointer); !ok || structOf(targetType) == nil {
+			return nil, nil, r.errorf(method.Pos, "%s.%s: updated target %s must be a pointer to a struct",
+				mapper.Name, method.Name, typeString(pkg, targetType))
+		}
+		method.Update = true
+		method.ReturnsError = results.Len() == 1
+		vars = vars[:len(vars)-1]
+	}
+
+	if targetType == nil {
+		return nil, nil, r.errorf(method.Pos, "%s.%s: method must take sources and return a target, or take sources and a target to update, got %s",
+			mapper.Name, method.Name, typeString(pkg, signature))
+	}
+
+	sources, err := r.resolveSources(pkg, mapper, method, vars)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sources, targetType, nil
+}
+
+// checkContext verifies that the method can pass a context to the conversion function when it takes one.
+func checkContext(method *model.MapperMethod, fn conversionFunc) error {
+	if fn.takesContext && !method.Context {
+		return fmt.Errorf("%s takes a context.Context, add one to the method", fn.expr)
+	}
+	return nil
+}
+
+// resolveNullValues marks the rules of an update method that leave their target field
+// unchanged when the source field is the zero value, according to the method's null value strategy,
+// or the mapper's one if the method has none.
+func (r *Resolver) resolveNullValues(mapper *model.MapperDefinition, method *model.MapperMethod) {
+	strategy := mapper.NullValueStrategy
+	if method.NullValueStrategy != "" {
+		strategy = method.NullValueStrategy
+	}
+	if !method.Update || strategy != model.NullValueSkip {
+		return
+	}
+
+	for i := range method.Mappings {
+		// Constants and expressions are always assigned
+		if rule := method.Mappings[i]; !rule.Ignore && rule.Constant == "" && rule.Expr == "" {
+			method.Mappings[i].SkipZero = true
+			// Zero values are detected by convert.IsZero
+			r.addImport(mapper, convertPackage, "convert")
+		}
+	}
+}
+
+// checkRules verifies that the fields of every explicit mapping rule exist and that
+// the source field can be assigned, or converted by a built-in conversion, to the target field.
+// Constant and default values, and expressions, must be assignable to the target field,
+// and a target field may only be mapped by a single rule. Methods that do not return an error
+// only apply a built-in conversion that can fail when the rule or the mapper selects it.
+// All the invalid rules of the method are reported at once.
+func (r *Resolver) checkRules(pkg *types.Package, mapper *model.MapperDefinition, method *model.MapperMethod, sources []source, targetType types.Type) error {
+	target := structOf(targetType)
+	if target == nil {
+		return nil
+	}
+
+	var errs []error
+	mapped := make(map[string]token.Pos)
+	for i := range method.Mappings {
+		rule := &method.Mappings[i]
+		targetField, allocations := lookupTargetPath(pkg, targetType, rule.TargetField)
+
+		if !rule.Ignore {
+			if pos, ok := mapped[rule.TargetField]; ok {
+				errs = append(errs, r.errorf(rule.Pos, "%s.%s: target field %s is already mapped at %s",
+					mapper.Name, method.Name, rule.TargetField, r.fset.Position(pos)))
+				continue
+			}
+			mapped[rule.TargetField] = rule.Pos
+		}
+
+		// Constants, expressions and target fields ignored by a tag are the only rules without a source field
+		var (
+			sourceType types.Type
+			sourceErr  error
+		)
+		if rule.SourceField != "" && rule.Constant == "" && rule.Expr == "" {
+			sourceType, rule.SourceExpr, rule.NilChecks, sourceErr = lookupSource(pkg, sources, rule.SourceField)
+		}
+
+		if rule.Ignore {
+			if sourceErr != nil && targetField == nil {
+				errs = append(errs, r.errorf(rule.Pos, "%s.%s: ignored field %s not found in sources or in %s",
+					mapper.Name, method.Name, rule.TargetField, typeString(pkg, targetType)))
+			}
+			continue
+		}
+
+		if targetField == nil {
+			errs = append(errs, r.errorf(rule.Pos, "%s.%s: target field %s not found in %s",
+				mapper.Name, method.Name, rule.TargetField, typeString(pkg, targetType)))
+		}
+		if sourceErr != nil {
+			errs = append(errs, r.errorf(rule.Pos, "%s.%s: %v", mapper.Name, method.Name, sourceErr))
+		}
+		if targetField == nil || sourceErr != nil {
+			continue
+		}
+
+		rule.TargetAllocations = nil
+		if len(allocations) > 0 && rule.Constant == "" && rule.Expr == "" {
+			// Allocations are guarded by convert.IsZero
+			r.addImport(mapper, convertPackage, "convert")
+		}
+		for _, alloc := range allocations {
+			rule.TargetAllocations = append(rule.TargetAllocations, model.Allocation{
+				Path: alloc.path,
+				Type: r.qualifiedType(pkg, mapper, alloc.elem),
+			})
+		}
+
+		if rule.Constant != "" {
+			value, err := r.checkValue(pkg, mapper, rule.Pos, rule.Constant, targetField.Type())
+			if err != nil {
+				errs = append(errs, r.errorf(rule.Pos, "%s.%s: target field %s: %v", mapper.Name, method.Name, rule.TargetField, err))
+				continue
+			}
+			rule.Constant = value
+			continue
+		}
+
+		if rule.Expr != "" {
+			value, reads, err := r.checkExpr(pkg, mapper, method, sources, rule.Pos, rule.Expr, targetField.Type())
+			if err != nil {
+				errs = append(errs, r.errorf(rule.Pos, "%s.%s: target field %s: %v", mapper.Name, method.Name, rule.TargetField, err))
+				continue
+			}
+			rule.Expr, rule.ExprReads = value, reads
+			rule.NilChecks = exprNilChecks(sources, reads)
+			continue
+		}
+
+		if rule.Default != "" {
+			value, err := r.checkValue(pkg, mapper, rule.Pos, rule.Default, targetField.Type())
+			if err != nil {
+				errs = append(errs, r.errorf(rule.Pos, "%s.%s: target field %s: %v", mapper.Name, method.Name, rule.TargetField, err))
+				continue
+			}
+			rule.Default = value
+			// Zero source fields are detected by convert.IsZero
+			r.addImport(mapper, convertPackage, "convert")
+		}
+
+		if rule.CustomFunc != "" {
+			customFunc, err := r.resolveFunc(pkg, mapper, rule.CustomFunc, sourceType, targetField.Type())
+			if err == nil {
+				err = checkContext(method, customFunc)
+			}
+			if err != nil {
+				errs = append(errs, r.errorf(rule.Pos, "%s.%s: %v", mapper.Name, method.Name, err))
+				continue
+			}
+			rule.CustomFunc, rule.CustomFuncError, rule.Context = customFunc.expr, customFunc.returnsError, customFunc.takesContext
+			continue
+		}
+
+		conversion, err := r.conversion(pkg, mapper, sourceType, targetField.Type(), rule.Convert)
+		if err != nil {
+			errs = append(errs, r.errorf(rule.Pos, "%s.%s: source field %s to target field %s: %v, use a using: function to convert it",
+				mapper.Name, method.Name, rule.SourceField, rule.TargetField, err))
+			continue
+		}
+		if err := checkContext(method, conversion); err != nil {
+			errs = append(errs, r.errorf(rule.Pos, "%s.%s: %v", mapper.Name, method.Name, err))
+			continue
+		}
+		if conversion.fallible && !method.ReturnsError {
+			errs = append(errs, r.errorf(rule.Pos, "%s.%s: source field %s to target field %s: converting %s to %s can fail, "+
+				"select the conversion with the convert: option or return an error from the method",
+				mapper.Name, method.Name, rule.SourceField, rule.TargetField, typeString(pkg, sourceType), typeString(pkg, targetField.Type())))
+			continue
+		}
+		rule.Conversion, rule.ConversionError, rule.Context = conversion.expr, conversion.returnsError, conversion.takesContext
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkUnmappedTargets reports the target fields that are neither mapped nor ignored
+// according to the mapper's unmapped target policy.
+func (r *Resolver) checkUnmappedTargets(pkg *types.Package, mapper *model.MapperDefinition, method *model.MapperMethod, targetType types.Type) error {
+	target := structOf(targetType)
+	if target == nil || mapper.UnmappedTarget == model.PolicyIgnore {
+		return nil
+	}
+
+	mapped := make(map[string]bool)
+	for _, rule := range method.Mappings {
+		root, _, _ := strings.Cut(rule.TargetField, ".")
+		mapped[root] = true
+	}
+
+	var unmapped []string
+	for i := 0; i < target.NumFields(); i++ {
+		targetField := target.Field(i)
+		if !mapped[targetField.Name()] && accessible(pkg, targetField) {
+			unmapped = append(unmapped, targetField.Name())
+		}
+	}
+
+	return r.report(mapper.UnmappedTarget, method.Pos, unmapped, "%s.%s: unmapped target fields of %s: %s",
+		mapper.Name, method.Name, typeString(pkg, targetType), strings.Join(unmapped, ", "))
+}
+
+// checkUnmappedSources reports the source fields that are neither read by a mapping nor ignored
+// according to the method's unmapped source policy, or the mapper's one if the method has none.
+func (r *Resolver) checkUnmappedSources(pkg *types.Package, mapper *model.MapperDefinition, method *model.MapperMethod, sources []source) error {
+	policy := mapper.UnmappedSource
+	if method.UnmappedSource != "" {
+		policy = method.UnmappedSource
+	}
+	if policy == model.PolicyIgnore {
+		return nil
+	}
+
+	// Sources are read through the expressions of the rules, such as "in.Address.City"
+	read := make(map[string]bool)
+	for _, rule := range method.Mappings {
+		for _, expr := range append([]string{rule.SourceExpr}, rule.ExprReads...) {
+			name, path, _ := strings.Cut(expr, ".")
+			root, _, _ := strings.Cut(path, ".")
+			read[name+"."+root] = true
+		}
+	}
+
+	var unmapped []string
+	for _, s := range sources {
+		source := structOf(s.typ)
+		if source == nil || read[s.name+"."] {
+			continue
+		}
+		for i := 0; i < source.NumFields(); i++ {
+			sourceField := source.Field(i)
+			if read[s.name+"."+sourceField.Name()] || !accessible(pkg, sourceField) {
+				continue
+			}
+			if len(sources) > 1 {
+				unmapped = append(unmapped, s.name+"."+sourceField.Name())
+			} else {
+				unmapped = append(unmapped, sourceField.Name())
+			}
+		}
+	}
+
+	return r.report(policy, method.Pos, unmapped, "%s.%s: unmapped source fields of %s: %s",
+		mapper.Name, method.Name, describeSources(pkg, sources), strings.Join(unmapped, ", "))
+}
+
+// report fails or warns about the problems found at pos according to the policy.
+// Nothing is reported when there are no problems.
+func (r *Resolver) report(policy model.ReportingPolicy, pos token.Pos, problems []string, format string, args ...interface{}) error {
+	if len(problems) == 0 {
+		return nil
+	}
+
+	switch policy {
+	case model.PolicyError:
+		return r.errorf(pos, format, args...)
+	case model.PolicyWarn:
+		log.Printf("Warning: %s", r.errorf(pos, format, args...))
+	}
+	return nil
+}
+
+// errorf returns an error prefixed with the file:line of pos, when pos is known.
+func (r *Resolver) errorf(pos token.Pos, format string, args ...interface{}) error {
+	if !pos.IsValid() {
+		return fmt.Errorf(format, args...)
+	}
+	return fmt.Errorf("%s: %s", r.fset.Position(pos), fmt.Sprintf(format, args...))
+}
+
+// lookupObject finds a package-level object by name, either declared in pkg ("TimeToUnix")
+// or exported by a package imported by the files of pkg ("timeutil.ToUnix").
+// Package qualifiers are resolved with the imports of the files, so import aliases are honored.
+func lookupObject(pkg *types.Package, name string) (types.Object, error) {
+	qualifier, objName, qualified := strings.Cut(name, ".")
+	if !qualified {
+		obj := pkg.Scope().Lookup(name)
+		if obj == nil {
+			return nil, fmt.Errorf("%s not found in package %s", name, pkg.Name())
+		}
+		return obj, nil
+	}
+
+	for i := 0; i < pkg.Scope().NumChildren(); i++ {
+		pkgName, ok := pkg.Scope().Child(i).Lookup(qualifier).(*types.PkgName)
+		if !ok {
+			continue
+		}
+
+		imported := pkgName.Imported()
+		obj := imported.Scope().Lookup(objName)
+		if obj == nil || !obj.Exported() {
+			return nil, fmt.Errorf("%s not found in package %s", objName, imported.Path())
+		}
+		return obj, nil
+	}
+
+	return nil, fmt.Errorf("package %s of %s is not imported", qualifier, name)
+}
+
+// lookupSignature returns the signature of the named interface method, or nil if there is none.
+func lookupSignature(iface *types.Interface, name string) *types.Signature {
+	for i := 0; i < iface.NumMethods(); i++ {
+		if fn := iface.Method(i); fn.Name() == name {
+			signature, _ := fn.Type().(*types.Signature)
+			return signature
+		}
+	}
+	return nil
+}
+
+// lookupField returns the named field of t, including promoted fields of embedded structs.
+// It returns nil when there is no such field accessible from pkg.
+func lookupField(pkg *types.Package, t types.Type, name string) *types.Var {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, pkg, name)
+	field, ok := obj.(*types.Var)
+	if !ok || !field.IsField() || !accessible(pkg, field) {
+		return nil
+	}
+	return field
+}
+
+// lookupPath returns the field at the end of a dotted path of fields of t, such as "Profile.Address.City".
+// It also returns the paths of the pointer fields traversed on the way, which must be checked
+// for nil before reading the field, e.g. ["Profile", "Profile.Address"].
+// It returns a nil field when the path does not exist.
+func lookupPath(pkg *types.Package, t types.Type, path string) (*types.Var, []string) {
+	var (
+		field     *types.Var
+		nilChecks []string
+	)
+
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		if field != nil {
+			t = field.Type()
+			if _, ok := t.Underlying().(*types.Pointer); ok {
+				nilChecks = append(nilChecks, strings.Join(names[:i], "."))
+			}
+		}
+
+		field = lookupField(pkg, t, name)
+		if field == nil {
+			return nil, nil
+		}
+	}
+
+	return field, nilChecks
+}
+
+// allocation is a pointer field traversed by a target path, which must be allocated
+// before the fields of the struct it points to can be set.
+type allocation struct {
+	path string
+	elem types.Type
+}
+
+// lookupTargetPath returns the field at the end of a dotted path of fields of the target type t,
+// such as "Address.City". Only fields declared directly in the structs are considered.
+// It also returns the pointer fields traversed on the way, which must be allocated before setting the field.
+// It returns a nil field when the path does not exist.
+func lookupTargetPath(pkg *types.Package, t types.Type, path string) (*types.Var, []allocation) {
+	var (
+		field       *types.Var
+		allocations []allocation
+	)
+
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		if field != nil {
+			t = field.Type()
+			if ptr, ok := t.Underlying().(*types.Pointer); ok {
+				allocations = append(allocations, allocation{path: strings.Join(names[:i], "."), elem: ptr.Elem()})
+			}
+		}
+
+		target := structOf(t)
+		if target == nil {
+			return nil, nil
+		}
+		field = lookupTargetField(pkg, target, name)
+		if field == nil {
+			return nil, nil
+		}
+	}
+
+	return field, allocations
+}
+
+// lookupTargetField returns the named field declared directly in the target struct.
+// It returns nil when there is no such field accessible from pkg.
+func lookupTargetField(pkg *types.Package, target *types.Struct, name string) *types.Var {
+	for i := 0; i < target.NumFields(); i++ {
+		if field := target.Field(i); field.Name() == name && accessible(pkg, field) {
+			return field
+		}
+	}
+	return nil
+}
+
+// typeString formats t relative to pkg.
+func typeString(pkg *types.Package, t types.Type) string {
+	return types.TypeString(t, types.RelativeTo(pkg))
+}
+
+// isNilable reports whether values of type t can be nil, e.g. pointers and interfaces.
+func isNilable(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Interface, *types.Slice, *types.Map, *types.Chan, *types.Signature:
+		return true
+	}
+	return false
+}
+
+// structOf returns the struct type behind t, following a single pointer indirection.
+func structOf(t types.Type) *types.Struct {
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	s, _ := t.Underlying().(*types.Struct)
+	return s
+}
+
+// accessible reports whether code generated in pkg can refer to the field.
+func accessible(pkg *types.Package, field *types.Var) bool {
+	return field.Exported() || field.Pkg() == pkg
+}
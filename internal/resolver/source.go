@@ -0,0 +1,148 @@
+package resolver
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"github.com/nduyhai/mapgen/internal/model"
+)
+
+// singleSource is the name of the source parameter of methods mapping a single source.
+const singleSource = "in"
+
+// reservedNames are the identifiers of the generated methods that source parameters must not shadow.
+var reservedNames = map[string]bool{"m": true, "out": true, "ctx": true, "_": true}
+
+// source is a parameter of a mapper method that fields are mapped from.
+type source struct {
+	// name is the name of the parameter in the generated code
+	name string
+	typ  types.Type
+}
+
+// resolveSources returns the source parameters of the method.
+// A single source is named "in" in the generated code; several sources keep their declared names,
+// so that mapping rules can select the parameter a field is read from, e.g. "a.City".
+func (r *Resolver) resolveSources(pkg *types.Package, mapper *model.MapperDefinition, method *model.MapperMethod, params []*types.Var) ([]source, error) {
+	if len(params) == 1 {
+		return []source{{name: singleSource, typ: params[0].Type()}}, nil
+	}
+
+	var (
+		sources []source
+		names   = make(map[string]bool)
+	)
+	for _, param := range params {
+		name := param.Name()
+		switch {
+		case name == "":
+			return nil, r.errorf(method.Pos, "%s.%s: parameters of methods with several sources must be named", mapper.Name, method.Name)
+		case reservedNames[name]:
+			return nil, r.errorf(method.Pos, "%s.%s: parameter name %s is reserved by the generated code", mapper.Name, method.Name, name)
+		case names[name]:
+			return nil, r.errorf(method.Pos, "%s.%s: duplicate parameter name %s", mapper.Name, method.Name, name)
+		}
+		names[name] = true
+		sources = append(sources, source{name: name, typ: param.Type()})
+	}
+	return sources, nil
+}
+
+// lookupSource returns the type of the source field at path, the expression reading it in the
+// generated code, and the expressions to check for nil before reading it.
+// With several sources, the path is either qualified by the parameter it is read from ("a.City"),
+// the name of a parameter itself ("a"), or a field found in exactly one of the parameters ("City").
+func lookupSource(pkg *types.Package, sources []source, path string) (types.Type, string, []string, error) {
+	if len(sources) == 1 {
+		return lookupSourceField(pkg, sources[0], path, false)
+	}
+
+	name, rest, qualified := strings.Cut(path, ".")
+	for _, s := range sources {
+		if s.name != name {
+			continue
+		}
+		if !qualified {
+			return s.typ, s.name, nil, nil
+		}
+		return lookupSourceField(pkg, s, rest, true)
+	}
+
+	var (
+		found []string
+		typ   types.Type
+		expr  string
+		nils  []string
+	)
+	for _, s := range sources {
+		if t, e, n, err := lookupSourceField(pkg, s, path, true); err == nil {
+			found = append(found, s.name)
+			typ, expr, nils = t, e, n
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, "", nil, fmt.Errorf("source field %s not found in %s", path, describeSources(pkg, sources))
+	case 1:
+		return typ, expr, nils, nil
+	default:
+		return nil, "", nil, fmt.Errorf("source field %s is found in parameters %s, qualify it with one of them, e.g. %s.%s",
+			path, strings.Join(found, ", "), found[0], path)
+	}
+}
+
+// lookupSourceField returns the type of the field at path in the source s, the expression reading it
+// and the expressions to check for nil before reading it, including s itself when guarded is set.
+func lookupSourceField(pkg *types.Package, s source, path string, guarded bool) (types.Type, string, []string, error) {
+	field, paths := lookupPath(pkg, s.typ, path)
+	if field == nil {
+		return nil, "", nil, fmt.Errorf("source field %s not found in %s", path, typeString(pkg, s.typ))
+	}
+
+	var nilChecks []string
+	if guarded && isNilable(s.typ) {
+		nilChecks = append(nilChecks, s.name)
+	}
+	for _, p := range paths {
+		nilChecks = append(nilChecks, s.name+"."+p)
+	}
+	return field.Type(), s.name + "." + path, nilChecks, nil
+}
+
+// exprNilChecks returns the sources to check for nil before evaluating an expression reading them, e.g. "u"
+// for "u.Name", so that a nil source skips the expression as it skips the fields read from it.
+// A single source is checked by the method itself, which returns before mapping anything.
+func exprNilChecks(sources []source, reads []string) []string {
+	if len(sources) == 1 {
+		return nil
+	}
+
+	var nilChecks []string
+	for _, s := range sources {
+		if !isNilable(s.typ) {
+			continue
+		}
+		for _, read := range reads {
+			if name, _, _ := strings.Cut(read, "."); name == s.name {
+				nilChecks = append(nilChecks, s.name)
+				break
+			}
+		}
+	}
+	return nilChecks
+}
+
+// describeSources formats the sources for error messages: the type of a single source,
+// or the names of several ones.
+func describeSources(pkg *types.Package, sources []source) string {
+	if len(sources) == 1 {
+		return typeString(pkg, sources[0].typ)
+	}
+
+	names := make([]string, len(sources))
+	for i, s := range sources {
+		names[i] = s.name
+	}
+	return "parameters " + strings.Join(names, ", ")
+}
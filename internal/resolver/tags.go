@@ -0,0 +1,179 @@
+package resolver
+
+import (
+	"fmt"
+	"go/types"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/nduyhai/mapgen/internal/model"
+)
+
+// tagKey is the key of the struct tags configuring the mapping of a field, e.g. `mapgen:"Name,using=TimeToUnix"`.
+const tagKey = "mapgen"
+
+// fieldTag is a mapgen struct tag.
+type fieldTag struct {
+	// name is the field on the other side of the mapping, empty for a field of the same name
+	name string
+	// ignore is set by the "-" tag, leaving the field out of the mapping
+	ignore  bool
+	using   string
+	convert model.Conversion
+}
+
+// parseTag parses the mapgen tag of a struct field: "-" ignores the field, otherwise the tag is the name
+// of the field on the other side of the mapping, optionally followed by comma-separated options,
+// e.g. "CreatedAt,using=TimeToUnix" or ",convert=unixMilli".
+func parseTag(tag string) (fieldTag, error) {
+	if tag == "-" {
+		return fieldTag{ignore: true}, nil
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+	parsed := fieldTag{name: name}
+	if options == "" {
+		return parsed, nil
+	}
+	for _, option := range strings.Split(options, ",") {
+		key, value, ok := strings.Cut(option, "=")
+		if !ok || value == "" {
+			return fieldTag{}, fmt.Errorf("invalid option %q, expected key=value", option)
+		}
+		switch key {
+		case "using":
+			parsed.using = value
+		case "convert":
+			parsed.convert = model.Conversion(value)
+			if !knownConversion(parsed.convert) {
+				return fieldTag{}, fmt.Errorf("unknown conversion %s", value)
+			}
+		default:
+			return fieldTag{}, fmt.Errorf("unknown option %s, expected one of: convert, using", key)
+		}
+	}
+	return parsed, nil
+}
+
+// tagRules adds the mapping rules declared by the mapgen tags of the target and source struct fields.
+//
+// Directives take precedence over tags, and tags of the target over tags of the sources, so a tag only
+// configures a target field that no directive configures:
+//   - a target field tagged `mapgen:"Name"` is mapped from the source field Name, or from a path such as
+//     "Address.City", and a target field tagged `mapgen:"-"` is ignored;
+//   - a source field tagged `mapgen:"Name"` is mapped to the target field Name, and a source field
+//     tagged `mapgen:"-"` is neither matched by name nor reported as unmapped.
+//
+// Using functions are looked up in the mapper's package, as for directives. Errors point to the tagged fields.
+func (r *Resolver) tagRules(pkg *types.Package, mapper *model.MapperDefinition, method *model.MapperMethod, sources []source, targetType types.Type) error {
+	target := structOf(targetType)
+	if target == nil {
+		return nil
+	}
+
+	configured := make(map[string]bool)
+	for _, rule := range method.Mappings {
+		root, _, _ := strings.Cut(rule.TargetField, ".")
+		configured[root] = true
+	}
+
+	var rules []model.FieldMappingRule
+	for i := 0; i < target.NumFields(); i++ {
+		field := target.Field(i)
+		tag, ok := reflect.StructTag(target.Tag(i)).Lookup(tagKey)
+		if !ok || configured[field.Name()] || !accessible(pkg, field) {
+			continue
+		}
+		parsed, err := parseTag(tag)
+		if err != nil {
+			return r.errorf(field.Pos(), "%s.%s: tag of target field %s: %v", mapper.Name, method.Name, field.Name(), err)
+		}
+
+		rule := parsed.rule(field.Name(), field.Name())
+		switch {
+		case parsed.ignore:
+			// Target fields ignored by a tag have no source field, the source field of the same name
+			// is still reported as unmapped
+			rule.SourceField = ""
+		case parsed.name != "":
+			rule.SourceField = parsed.name
+		}
+		rule.Pos = field.Pos()
+		rules = append(rules, rule)
+		configured[field.Name()] = true
+	}
+
+	// Several source fields tagged with the same target field are reported rather than picked arbitrarily
+	tagged := make(map[string][]string)
+	for _, s := range sources {
+		source := structOf(s.typ)
+		if source == nil {
+			continue
+		}
+		for i := 0; i < source.NumFields(); i++ {
+			field := source.Field(i)
+			tag, ok := reflect.StructTag(source.Tag(i)).Lookup(tagKey)
+			if !ok || !accessible(pkg, field) {
+				continue
+			}
+			parsed, err := parseTag(tag)
+			if err != nil {
+				return r.errorf(field.Pos(), "%s.%s: tag of source field %s: %v", mapper.Name, method.Name, field.Name(), err)
+			}
+
+			sourceField := field.Name()
+			if len(sources) > 1 {
+				sourceField = s.name + "." + sourceField
+			}
+			if parsed.ignore {
+				// Source fields ignored by a tag have no target field
+				rules = append(rules, model.FieldMappingRule{SourceField: sourceField, Ignore: true, Pos: field.Pos()})
+				continue
+			}
+
+			rule := parsed.rule(sourceField, field.Name())
+			if parsed.name != "" {
+				rule.TargetField = parsed.name
+			}
+			root, _, _ := strings.Cut(rule.TargetField, ".")
+			if configured[root] {
+				continue
+			}
+			rule.Pos = field.Pos()
+			rules = append(rules, rule)
+			tagged[rule.TargetField] = append(tagged[rule.TargetField], sourceField)
+		}
+	}
+
+	for _, targetField := range slices.Sorted(maps.Keys(tagged)) {
+		if sourceFields := tagged[targetField]; len(sourceFields) > 1 {
+			return r.errorf(method.Pos, "%s.%s: target field %s is tagged by source fields %s, add a mapping to select one",
+				mapper.Name, method.Name, targetField, strings.Join(sourceFields, ", "))
+		}
+	}
+
+	method.Mappings = append(method.Mappings, rules...)
+	return nil
+}
+
+// rule returns the mapping rule of the tag from sourceField to targetField.
+func (t fieldTag) rule(sourceField, targetField string) model.FieldMappingRule {
+	return model.FieldMappingRule{
+		SourceField: sourceField,
+		TargetField: targetField,
+		Ignore:      t.ignore,
+		CustomFunc:  t.using,
+		Convert:     t.convert,
+	}
+}
+
+// knownConversion reports whether c names a built-in conversion.
+func knownConversion(c model.Conversion) bool {
+	switch c {
+	case model.ConversionAuto, model.ConversionNone, model.ConversionUnixMilli:
+		return true
+	}
+	return slices.Contains(defaultConversions, c)
+}
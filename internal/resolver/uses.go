@@ -0,0 +1,92 @@
+package resolver
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/nduyhai/mapgen/internal/model"
+)
+
+// resolveUses looks up the mapper interfaces used by the mapper and names the
+// implementation fields they are injected into. Errors point to the mapper interface at pos.
+func (r *Resolver) resolveUses(pkg *types.Package, mapper *model.MapperDefinition, pos token.Pos) error {
+	fields := make(map[string]bool)
+	for i := range mapper.Uses {
+		used := &mapper.Uses[i]
+
+		obj, err := lookupObject(pkg, used.Name)
+		if err != nil {
+			return r.errorf(pos, "%s: used mapper %v", mapper.Name, err)
+		}
+		if _, ok := obj.(*types.TypeName); !ok || !types.IsInterface(obj.Type()) {
+			return r.errorf(pos, "%s: used mapper %s is not an interface", mapper.Name, used.Name)
+		}
+
+		// Mappers with the same name from different packages get numbered fields
+		base := strings.ToLower(obj.Name()[:1]) + obj.Name()[1:]
+		field := base
+		for n := 2; fields[field]; n++ {
+			field = fmt.Sprintf("%s%d", base, n)
+		}
+		fields[field] = true
+
+		used.Field = field
+		used.Type = r.qualifiedType(pkg, mapper, obj.Type())
+	}
+	return nil
+}
+
+// lookupMapperMethod returns the method that maps src to dst, either a method of the mapper ("m.ToDTO")
+// or of a mapper it uses ("m.addressMapper.ToDTO"), with an empty expression if there is none.
+// Methods of the mapper come first, and methods whose types match exactly are preferred.
+func lookupMapperMethod(pkg *types.Package, mapper *model.MapperDefinition, src, dst types.Type) conversionFunc {
+	if method := matchMethod(pkg, mapper.Name, "m.", src, dst); method.expr != "" {
+		return method
+	}
+	for _, used := range mapper.Uses {
+		if method := matchMethod(pkg, used.Name, "m."+used.Field+".", src, dst); method.expr != "" {
+			return method
+		}
+	}
+	return conversionFunc{}
+}
+
+// matchMethod returns the method of the named interface that maps src to dst, its expression being prefix
+// followed by the name of the method, or an empty expression if there is none.
+// Methods may take a context.Context before the value and return an error after the result.
+// Methods whose types match exactly are preferred.
+func matchMethod(pkg *types.Package, name, prefix string, src, dst types.Type) conversionFunc {
+	obj, err := lookupObject(pkg, name)
+	if err != nil {
+		return conversionFunc{}
+	}
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return conversionFunc{}
+	}
+
+	var assignable conversionFunc
+	for i := 0; i < iface.NumMethods(); i++ {
+		fn := iface.Method(i)
+		signature := fn.Type().(*types.Signature)
+		params, results := signature.Params(), signature.Results()
+
+		takesContext := params.Len() == 2 && isContext(params.At(0).Type())
+		returnsError := results.Len() == 2 && types.Identical(results.At(1).Type(), errorType)
+		if (params.Len() != 1 && !takesContext) || (results.Len() != 1 && !returnsError) {
+			continue
+		}
+
+		param, result := params.At(params.Len()-1).Type(), results.At(0).Type()
+		method := conversionFunc{expr: prefix + fn.Name(), returnsError: returnsError, takesContext: takesContext}
+		if types.Identical(param, src) && types.Identical(result, dst) {
+			return method
+		}
+		if assignable.expr == "" && types.AssignableTo(src, param) && types.AssignableTo(result, dst) {
+			assignable = method
+		}
+	}
+	return assignable
+}
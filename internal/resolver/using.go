@@ -0,0 +1,65 @@
+package resolver
+
+import (
+	"fmt"
+	"go/types"
+
+	"github.com/nduyhai/mapgen/internal/model"
+)
+
+// errorType is the predeclared error type.
+var errorType = types.Universe.Lookup("error").Type()
+
+// resolveFunc looks up the converter function of a using: rule, either a function of
+// pkg ("TimeToUnix") or a function of a package imported by the mapper's file ("timeutil.ToUnix").
+// The function must take a value of type src, optionally preceded by a context.Context,
+// and return a value assignable to dst, optionally followed by an error.
+func (r *Resolver) resolveFunc(pkg *types.Package, mapper *model.MapperDefinition, name string, src, dst types.Type) (conversionFunc, error) {
+	fn, err := lookupFunc(pkg, name)
+	if err != nil {
+		return conversionFunc{}, err
+	}
+	expr := fn.Name()
+	if fn.Pkg() != pkg {
+		expr = r.addImport(mapper, fn.Pkg().Path(), fn.Pkg().Name()) + "." + expr
+	}
+
+	signature := fn.Type().(*types.Signature)
+	if signature.TypeParams().Len() > 0 {
+		return conversionFunc{}, fmt.Errorf("converter %s must not be generic", name)
+	}
+
+	params, results := signature.Params(), signature.Results()
+	takesContext := params.Len() == 2 && isContext(params.At(0).Type())
+	if (params.Len() != 1 && !takesContext) || signature.Variadic() {
+		return conversionFunc{}, fmt.Errorf("converter %s must take exactly one parameter, optionally preceded by a context.Context, got %s", name, typeString(pkg, signature))
+	}
+	param := params.At(params.Len() - 1).Type()
+	if !types.AssignableTo(src, param) {
+		return conversionFunc{}, fmt.Errorf("converter %s takes %s, cannot pass %s", name, typeString(pkg, param), typeString(pkg, src))
+	}
+
+	returnsError := results.Len() == 2 && types.Identical(results.At(1).Type(), errorType)
+	if results.Len() != 1 && !returnsError {
+		return conversionFunc{}, fmt.Errorf("converter %s must return a single value, optionally followed by an error, got %s", name, typeString(pkg, signature))
+	}
+	if !types.AssignableTo(results.At(0).Type(), dst) {
+		return conversionFunc{}, fmt.Errorf("converter %s returns %s, cannot assign it to %s", name, typeString(pkg, results.At(0).Type()), typeString(pkg, dst))
+	}
+
+	return conversionFunc{expr: expr, returnsError: returnsError, takesContext: takesContext}, nil
+}
+
+// lookupFunc finds the named function.
+func lookupFunc(pkg *types.Package, name string) (*types.Func, error) {
+	obj, err := lookupObject(pkg, name)
+	if err != nil {
+		return nil, fmt.Errorf("converter %w", err)
+	}
+
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return nil, fmt.Errorf("converter %s is not a function", name)
+	}
+	return fn, nil
+}
@@ -1,20 +1,28 @@
 package scanner
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
-	"go/importer"
 	"go/parser"
 	"go/token"
 	"go/types"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
 )
 
+// generatedHeader is the first line of the files generated by mapgen.
+const generatedHeader = "// Code generated by mapgen. DO NOT EDIT."
+
 // Scanner is responsible for parsing Go source files into ASTs and performing type checking.
 type Scanner struct {
 	// FileSet provides position information for AST nodes
 	fset *token.FileSet
+	// buildTags are the build tags used to select the files of a package
+	buildTags []string
 }
 
 // NewScanner creates a new Scanner instance.
@@ -25,6 +33,11 @@ func NewScanner() *Scanner {
 	}
 }
 
+// SetBuildTags sets the build tags used when loading packages.
+func (s *Scanner) SetBuildTags(tags []string) {
+	s.buildTags = tags
+}
+
 // ParseFile parses a single Go source file into an AST
 func (s *Scanner) ParseFile(filePath string) (*ast.File, error) {
 	// Check if a file exists
@@ -41,57 +54,156 @@ func (s *Scanner) ParseFile(filePath string) (*ast.File, error) {
 	return file, nil
 }
 
+// Package is a parsed and type-checked Go package.
+type Package struct {
+	// Name is the package name declared in the source files
+	Name string
+	// Path is the import path of the package
+	Path string
+	// Dir is the directory of the package's source files
+	Dir string
+	// Files are the parsed source files of the package
+	Files []*ast.File
+	// Types is the type-checked package
+	Types *types.Package
+	// Info holds the type information computed for Files
+	Info *types.Info
+	// TypeErrors are the errors found while type checking the package, whose type information
+	// is then incomplete
+	TypeErrors []types.Error
+}
+
 // ParseDir parses all Go source files in a directory and converts them to types.Package.
 func (s *Scanner) ParseDir(dirPath string) ([]*types.Package, error) {
+	pkgs, err := s.LoadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	typesPkgs := make([]*types.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		typesPkgs = append(typesPkgs, pkg.Types)
+	}
+
+	return typesPkgs, nil
+}
+
+// LoadDir parses and type checks the Go package in a directory.
+// Unlike ParseDir, it keeps the ASTs and the type information of every package.
+//
+// Packages are loaded through the go command, so imports are resolved the same
+// way go build does: module-local packages, go.mod replace directives, vendoring
+// and the scanner's build tags are all honored.
+// A directory without Go source files yields no package.
+//
+// Files previously generated by mapgen in the directory are loaded without their declarations,
+// so stale implementations of a mapper that has since changed do not prevent regenerating them.
+// Type errors do not fail the loading either: the package keeps them in TypeErrors along with
+// the type information of everything else.
+func (s *Scanner) LoadDir(dirPath string) ([]*Package, error) {
 	// Check if a directory exists
 	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("directory does not exist: %s", dirPath)
 	}
 
-	// Parse the directory to get AST packages
-	astPkgs, err := parser.ParseDir(s.fset, dirPath, nil, parser.ParseComments)
+	goFiles, err := filepath.Glob(filepath.Join(dirPath, "*.go"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse directory %s: %w", dirPath, err)
+		return nil, fmt.Errorf("failed to list Go files in %s: %w", dirPath, err)
+	}
+	if len(goFiles) == 0 {
+		return nil, nil
 	}
+	return s.load(dirPath, ".", func(dir, absDir string) bool {
+		return dir == absDir
+	})
+}
 
-	// Convert ast.Package to types.Package
-	var typesPkgs []*types.Package
-	for name, astPkg := range astPkgs {
-		// Create a new types.Package
-		typesPkg := types.NewPackage(dirPath, name)
+// LoadTree parses and type checks the Go packages in a directory and its subdirectories, as matched
+// by the pattern ./... in dirPath. As with the go command, the testdata and vendor directories and
+// the directories whose name starts with '.' or '_' are left out.
+//
+// Files previously generated by mapgen anywhere in the tree are loaded without their declarations,
+// and type errors are kept in TypeErrors, as with LoadDir.
+func (s *Scanner) LoadTree(dirPath string) ([]*Package, error) {
+	// Check if a directory exists
+	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", dirPath)
+	}
 
-		// Convert package.Files map to a slice of *ast.File
-		var files []*ast.File
-		for _, file := range astPkg.Files {
-			files = append(files, file)
-		}
+	return s.load(dirPath, "./...", func(dir, absDir string) bool {
+		return dir == absDir || strings.HasPrefix(dir, absDir+string(filepath.Separator))
+	})
+}
 
-		// Create type info for this check
-		typeInfo := &types.Info{
-			Types:      make(map[ast.Expr]types.TypeAndValue),
-			Defs:       make(map[*ast.Ident]types.Object),
-			Uses:       make(map[*ast.Ident]types.Object),
-			Implicits:  make(map[ast.Node]types.Object),
-			Selections: make(map[*ast.SelectorExpr]*types.Selection),
-			Scopes:     make(map[ast.Node]*types.Scope),
-		}
+// load loads the packages matched by pattern in dirPath with a single go command invocation.
+// The generated files of the directories for which inTree reports true are parsed
+// with their package clause only.
+func (s *Scanner) load(dirPath, pattern string, inTree func(dir, absDir string) bool) ([]*Package, error) {
+	absDir, err := filepath.Abs(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve absolute path for %s: %w", dirPath, err)
+	}
 
-		// Create type config with the default importer
-		typeConfig := &types.Config{
-			Importer: importer.Default(),
-			Error:    func(err error) {}, // Silently collect errors
-		}
+	config := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports |
+			packages.NeedDeps | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
+		Dir:  dirPath,
+		Fset: s.fset,
+		ParseFile: func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+			if inTree(filepath.Dir(filename), absDir) && isGenerated(src) {
+				return parser.ParseFile(fset, filename, src, parser.PackageClauseOnly)
+			}
+			return parser.ParseFile(fset, filename, src, parser.ParseComments)
+		},
+	}
+	if len(s.buildTags) > 0 {
+		config.BuildFlags = []string{"-tags=" + strings.Join(s.buildTags, ",")}
+	}
 
-		err = types.NewChecker(typeConfig, s.fset, typesPkg, typeInfo).Files(files)
-		if err != nil {
-			// Continue with other packages even if one fails
-			fmt.Printf("Warning: type checking error in package %s: %v\n", name, err)
+	loaded, err := packages.Load(config, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages in %s: %w", dirPath, err)
+	}
+
+	var pkgs []*Package
+	for _, pkg := range loaded {
+		// Type errors are left to the caller, which knows the declarations it depends on
+		var errs []error
+		for _, pkgErr := range pkg.Errors {
+			if pkgErr.Kind != packages.TypeError {
+				errs = append(errs, pkgErr)
+			}
+		}
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("failed to load package %s: %w", pkg.PkgPath, errors.Join(errs...))
 		}
 
-		typesPkgs = append(typesPkgs, typesPkg)
+		pkgs = append(pkgs, &Package{
+			Name:       pkg.Name,
+			Path:       pkg.PkgPath,
+			Dir:        pkg.Dir,
+			Files:      pkg.Syntax,
+			Types:      pkg.Types,
+			Info:       pkg.TypesInfo,
+			TypeErrors: pkg.TypeErrors,
+		})
 	}
 
-	return typesPkgs, nil
+	return pkgs, nil
+}
+
+// isGenerated reports whether src is a file generated by mapgen, whose header precedes the package clause.
+func isGenerated(src []byte) bool {
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimSpace(line)
+		if line == generatedHeader {
+			return true
+		}
+		if strings.HasPrefix(line, "package ") {
+			return false
+		}
+	}
+	return false
 }
 
 // ParsePackage parses all Go source files in a package and converts them to types.Package.
@@ -110,40 +222,3 @@ func (s *Scanner) ParsePackage(pkgPath string) ([]*types.Package, error) {
 func (s *Scanner) GetFileSet() *token.FileSet {
 	return s.fset
 }
-
-// TypeCheckFile parses and type checks a single Go source file.
-// It returns the AST, type information, and any error that occurred.
-func (s *Scanner) TypeCheckFile(filePath string) (*ast.File, *types.Info, error) {
-	// Parse the file first
-	file, err := s.ParseFile(filePath)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	// Create a new package to type check the file
-	pkg := types.NewPackage(filepath.Dir(filePath), file.Name.Name)
-
-	// Create type info for this check
-	typeInfo := &types.Info{
-		Types:      make(map[ast.Expr]types.TypeAndValue),
-		Defs:       make(map[*ast.Ident]types.Object),
-		Uses:       make(map[*ast.Ident]types.Object),
-		Implicits:  make(map[ast.Node]types.Object),
-		Selections: make(map[*ast.SelectorExpr]*types.Selection),
-		Scopes:     make(map[ast.Node]*types.Scope),
-	}
-
-	// Create type config with the default importer
-	typeConfig := &types.Config{
-		Importer: importer.Default(),
-		Error:    func(err error) {}, // Silently collect errors
-	}
-
-	// Type checks the file
-	err = types.NewChecker(typeConfig, s.fset, pkg, typeInfo).Files([]*ast.File{file})
-	if err != nil {
-		return file, typeInfo, fmt.Errorf("type checking error: %w", err)
-	}
-
-	return file, typeInfo, nil
-}
@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeModule writes a module made of the files to a temporary directory and returns it.
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/scanned\n\ngo 1.24\n"
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadDirSkipsGeneratedFiles(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"user.go": "package scanned\n\ntype User struct{ Name string }\n",
+		// The implementation of a mapper whose target no longer has the field City
+		"userMapper.gen.go": generatedHeader + "\npackage scanned\n\nfunc toUser(in User) User {\n\tout := User{}\n\tout.City = in.City\n\treturn out\n}\n",
+	})
+
+	pkgs, err := NewScanner().LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+	if len(pkgs) != 1 {
+		t.Fatalf("LoadDir() returned %d packages, want 1", len(pkgs))
+	}
+	if obj := pkgs[0].Types.Scope().Lookup("toUser"); obj != nil {
+		t.Errorf("LoadDir() loaded %s from the generated file", obj)
+	}
+	if obj := pkgs[0].Types.Scope().Lookup("User"); obj == nil {
+		t.Errorf("LoadDir() did not load User")
+	}
+}
+
+func TestLoadDirKeepsTypeErrors(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"user.go": "package scanned\n\ntype User struct{ Name string }\n",
+		// Only files generated by mapgen are skipped, whatever their name
+		"user.gen.go": "// Code generated by another tool. DO NOT EDIT.\npackage scanned\n\nvar name int = \"name\"\n",
+	})
+
+	pkgs, err := NewScanner().LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+	if len(pkgs) != 1 {
+		t.Fatalf("LoadDir() returned %d packages, want 1", len(pkgs))
+	}
+	if len(pkgs[0].TypeErrors) != 1 || !strings.Contains(pkgs[0].TypeErrors[0].Error(), "user.gen.go") {
+		t.Errorf("LoadDir() type errors = %v, want a type error in user.gen.go", pkgs[0].TypeErrors)
+	}
+	if obj := pkgs[0].Types.Scope().Lookup("User"); obj == nil {
+		t.Errorf("LoadDir() did not load User")
+	}
+}
+
+func TestLoadTree(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"user.go": "package scanned\n\ntype User struct{ Name string }\n",
+	})
+	for _, sub := range []string{"dto", "testdata", "vendor/example.com/lib", "_old", ".cache"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+		src := "package " + strings.Trim(filepath.Base(sub), "._") + "\n"
+		if err := os.WriteFile(filepath.Join(dir, sub, "doc.go"), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkgs, err := NewScanner().LoadTree(dir)
+	if err != nil {
+		t.Fatalf("LoadTree() error = %v", err)
+	}
+	var paths []string
+	for _, pkg := range pkgs {
+		paths = append(paths, pkg.Path)
+	}
+	if want := []string{"example.com/scanned", "example.com/scanned/dto"}; !slices.Equal(paths, want) {
+		t.Errorf("LoadTree() loaded %q, want %q", paths, want)
+	}
+}
+
+func TestIsGenerated(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want bool
+	}{
+		{name: "header", src: generatedHeader + "\npackage mapper\n", want: true},
+		{name: "header after a comment", src: "//go:build tools\n\n" + generatedHeader + "\n\npackage mapper\n", want: true},
+		{name: "header after package clause", src: "package mapper\n\n" + generatedHeader + "\n", want: false},
+		{name: "other generator", src: "// Code generated by stringer. DO NOT EDIT.\npackage mapper\n", want: false},
+		{name: "no header", src: "package mapper\n", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isGenerated([]byte(tt.src)); got != tt.want {
+				t.Errorf("isGenerated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+// Package templates holds the templates of the generated code, embedded in the mapgen binary.
+package templates
+
+import "embed"
+
+// FS holds the templates, named after their file, e.g. "mapper_impl.tmpl".
+//
+//go:embed *.tmpl
+var FS embed.FS
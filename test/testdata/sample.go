@@ -6,12 +6,13 @@ import (
 )
 
 type User struct {
-	ID        int
-	UserName  string
-	FirstName string
-	LastName  string
-	Email     string
-	CreatedAt time.Time
+	ID           int
+	UserName     string
+	FirstName    string
+	LastName     string
+	Email        string
+	CreatedAt    time.Time
+	PasswordHash string
 }
 
 type UserDTO struct {
@@ -37,9 +38,7 @@ type UserMapper interface {
 
 // +mapgen:mapper impl:addressDtoMapper target:address_dto_mapper.go
 type AddressDtoMapper interface {
-	// +mapgen:mapping from:UserName to:Name
-	// +mapgen:mapping from:CreatedAt to:CreatedAt using:TimeToUnix
-	// +mapgen:mapping ignore:PasswordHash
+	// +mapgen:mapping ignore:Created
 	ToDTO(address *fancy.Address) *AddressDto
 }
 type AddressDto struct {